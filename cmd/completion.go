@@ -0,0 +1,77 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Output shell completion code",
+	Long: `Output shell completion code for the given shell.
+
+Wiki names and other dynamic values are completed in bash and fish.
+
+  bash:       source <(mw completion bash)
+  zsh:        source <(mw completion zsh)
+  fish:       mw completion fish | source
+  powershell: mw completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Args:      cobra.ExactValidArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		switch args[0] {
+		case "bash":
+			rootCmd.GenBashCompletion(os.Stdout)
+		case "zsh":
+			rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			rootCmd.GenPowerShellCompletion(os.Stdout)
+		}
+	},
+}
+
+/*mwddWikiNameCompletion completes the first argument with the names of recorded wikis*/
+func mwddWikiNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return mwdd.DefaultForUser().UsedDBNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+/*mwddWikiNameThenFileCompletion completes the first argument with the names of recorded wikis, and the second with files*/
+func mwddWikiNameThenFileCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return mwddWikiNameCompletion(cmd, args, toComplete)
+}
+
+/*mwddWikiNameFlagCompletion completes a flag with the names of recorded wikis*/
+func mwddWikiNameFlagCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return mwdd.DefaultForUser().UsedDBNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
@@ -36,19 +36,52 @@ var mwddCmd = &cobra.Command{
 		mwdd := mwdd.DefaultForUser()
 		mwdd.EnsureReady()
 		if mwdd.Env().Missing("PORT") {
+			defaultPort, err := ports.FreeUpFrom("8080")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Could not find a free port to suggest: "+err.Error())
+			}
 			prompt := promptui.Prompt{
+				Stdout:   os.Stderr,
 				Label:    "What port would you like to use for your development environment?",
-				Default:  ports.FreeUpFrom("8080"),
+				Default:  defaultPort,
 				Validate: ports.IsValidAndFree,
 			}
 			value, err := prompt.Run()
 			if err == nil {
 				mwdd.Env().Set("PORT", value)
 			} else {
-				fmt.Println("Can't continue without a port")
+				fmt.Fprintln(os.Stderr, "Can't continue without a port")
 				os.Exit(1)
 			}
 		}
+		// Something else may have started listening on our ports since they were chosen
+		if cmd.Name() == "create" || cmd.Name() == "resume" || cmd.Name() == "quickstart" {
+			keys := []string{"PORT"}
+			if mwdd.HTTPSEnabled() {
+				keys = append(keys, "HTTPS_PORT")
+			}
+			mwddEnsurePortsUsable(keys)
+		}
+		if err := mwdd.EnsureNetworkSettings(); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid network settings in "+mwdd.Env().Path())
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := mwdd.EnsureDBCredentials(); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to generate database credentials")
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if inUse := mwdd.NetworkSubnetInUse(); inUse != "" && inUse != mwdd.Env().Get("NETWORK_SUBNET") {
+			fmt.Fprintln(os.Stderr, "WARNING: The existing docker network uses "+inUse+" but NETWORK_SUBNET is "+mwdd.Env().Get("NETWORK_SUBNET"))
+			fmt.Fprintln(os.Stderr, "The network will need to be recreated, for example using the destroy command, before the new subnet is used.")
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// Read by Special:Mwdd
+		if err := mwdd.DefaultForUser().WriteStatus(Version); err != nil && Verbosity >= 2 {
+			fmt.Println("Failed to write the environment status:", err)
+		}
 	},
 }
 
@@ -68,6 +101,7 @@ var mwddDestroyCmd = &cobra.Command{
 			Verbosity: Verbosity,
 		}
 		mwdd.DefaultForUser().DownWithVolumesAndOrphans(options)
+		mwdd.DefaultForUser().ForgetWikisOfType("")
 	},
 }
 
@@ -0,0 +1,115 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+/*mwddDBVersionCmd a version command for a database service, changing the image and handling the existing data*/
+func mwddDBVersionCmd(name string, example string) *cobra.Command {
+	dbImage := mwdd.DBImages[name]
+
+	return &cobra.Command{
+		Use:     "version [version]",
+		Short:   "Shows or changes the version of " + name + " that is used",
+		Example: example,
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			current := mwdd.DefaultForUser().DBImage(name)
+			if len(args) == 0 {
+				fmt.Println(current)
+				return
+			}
+
+			image, err := dbImage.Parse(args[0])
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if image == current {
+				fmt.Println(name + " already uses " + image)
+				return
+			}
+
+			options := exec.HandlerOptions{
+				Verbosity: Verbosity,
+			}
+			running := []string{}
+			for _, service := range dbImage.Services {
+				if mwdd.DefaultForUser().ServiceIsRunning(service) {
+					running = append(running, service)
+				}
+			}
+			existing := []string{}
+			for _, volume := range dbImage.DataVolumes {
+				exists, err := mwdd.DefaultForUser().VolumeExists(volume)
+				if err != nil {
+					fmt.Println("Unable to check for existing " + name + " data, nothing has been changed:")
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				if exists {
+					existing = append(existing, volume)
+				}
+			}
+
+			if len(existing) > 0 {
+				fmt.Println("WARNING: Data created by " + current + " may not work with " + image + ".")
+				fmt.Println("Downgrading, or switching between flavors, usually needs the data to be wiped.")
+				prompt := promptui.Prompt{
+					Label:     "Do you want to snapshot and wipe the existing data?",
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err == nil {
+					for _, volume := range existing {
+						snapshot, err := mwdd.DefaultForUser().SnapshotVolume(volume, options)
+						if err != nil {
+							fmt.Println("Failed to snapshot " + volume + ", nothing has been wiped:")
+							fmt.Println(err)
+							os.Exit(1)
+						}
+						fmt.Println("Snapshot of " + volume + ": " + snapshot)
+					}
+					mwdd.DefaultForUser().Rm(dbImage.Services, options)
+					for _, volume := range append(existing, dbImage.StateVolumes...) {
+						if exists, _ := mwdd.DefaultForUser().VolumeExists(volume); exists {
+							mwdd.DefaultForUser().RmVolumes([]string{volume}, options)
+						}
+					}
+					mwdd.DefaultForUser().ForgetWikisOfType(name)
+				} else {
+					fmt.Println("Keeping the existing data")
+				}
+			}
+
+			mwdd.DefaultForUser().Env().Set(dbImage.EnvKey, image)
+			if len(running) > 0 {
+				mwdd.DefaultForUser().UpDetached(running, options)
+			}
+			fmt.Println(name + " now uses " + image)
+		},
+	}
+}
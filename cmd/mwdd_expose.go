@@ -0,0 +1,121 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/ports"
+	"github.com/spf13/cobra"
+)
+
+func mwddRecreateIfRunning(service string) {
+	if mwdd.DefaultForUser().ServiceIsRunning(service) {
+		mwdd.DefaultForUser().Recreate([]string{service}, exec.HandlerOptions{
+			Verbosity: Verbosity,
+		})
+	}
+}
+
+// mwddExposePort picks the host port to expose on, either the requested one or a free one that isn't used by the environment
+func mwddExposePort(service mwdd.ExposableService, requested string) (string, error) {
+	if requested != "" {
+		if mwdd.DefaultForUser().ServicePublishesPort(service.Service, requested) {
+			return requested, nil
+		}
+		return requested, ports.IsValidAndFreeOn(ports.Localhost, requested)
+	}
+	if current := mwdd.DefaultForUser().ExposedPort(service.Service); current != "" {
+		return current, nil
+	}
+
+	env := mwdd.DefaultForUser().Env()
+	taken := append(mwdd.DefaultForUser().ExposedPorts(), env.Get("PORT"), env.Get("HTTPS_PORT"))
+	free, err := ports.FreeSet(ports.Localhost, []ports.Range{ports.RangeFrom(service.DefaultHostPort)}, taken)
+	if err != nil {
+		return "", err
+	}
+	return free[0], nil
+}
+
+/*mwddExposeCmds expose, unexpose and status commands for a service that can have its port published on the host*/
+func mwddExposeCmds(name string) []*cobra.Command {
+	service := mwdd.ExposableServices[name]
+	var port string
+
+	expose := &cobra.Command{
+		Use:   "expose",
+		Short: "Publish the " + name + " port on the host, for use by local tools",
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			hostPort, err := mwddExposePort(service, port)
+			if err != nil && port != "" {
+				fmt.Println("Can't expose " + name + " on port " + port + ": " + err.Error())
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Println("Can't find a free port to expose " + name + " on: " + err.Error())
+				os.Exit(1)
+			}
+			if err := mwdd.DefaultForUser().Expose(name, hostPort); err != nil {
+				fmt.Println("Failed to expose " + name + ": " + err.Error())
+				os.Exit(1)
+			}
+			mwddRecreateIfRunning(name)
+			fmt.Println(name + " exposed: " + mwdd.DefaultForUser().ConnectionString(name))
+		},
+	}
+	expose.Flags().StringVarP(&port, "port", "p", "", "Host port to use, defaults to a free port from "+service.DefaultHostPort)
+
+	unexpose := &cobra.Command{
+		Use:   "unexpose",
+		Short: "Stop publishing the " + name + " port on the host",
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			if err := mwdd.DefaultForUser().Unexpose(name); err != nil {
+				fmt.Println("Failed to unexpose " + name + ": " + err.Error())
+				os.Exit(1)
+			}
+			mwddRecreateIfRunning(name)
+			fmt.Println(name + " is no longer exposed")
+		},
+	}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Shows if " + name + " is running and how to connect to it",
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			if mwdd.DefaultForUser().ServiceIsRunning(name) {
+				fmt.Println(name + " is running")
+			} else {
+				fmt.Println(name + " is not running")
+			}
+			if connection := mwdd.DefaultForUser().ConnectionString(name); connection != "" {
+				fmt.Println("Exposed on the host: " + connection)
+			} else {
+				fmt.Println("Not exposed on the host, use the expose command")
+			}
+		},
+	}
+
+	return []*cobra.Command{expose, unexpose, status}
+}
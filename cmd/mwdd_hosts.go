@@ -19,9 +19,11 @@ package cmd
 
 import (
 	"fmt"
+	"runtime"
 
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/hosts"
+	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 )
 
@@ -31,56 +33,75 @@ var mwddHostsCmd = &cobra.Command{
 	RunE:  nil,
 }
 
+func mwddHostsPrintSave(save hosts.Save) {
+	if save.Success {
+		fmt.Println("Hosts file updated!")
+		if save.BackupFile != "" {
+			fmt.Println("Previous hosts file backed up to: " + save.BackupFile)
+		}
+		return
+	}
+
+	fmt.Println("Could not save your hosts file.")
+	if runtime.GOOS != "windows" {
+		prompt := promptui.Prompt{
+			Label:     "Do you want to write it using sudo?",
+			IsConfirm: true,
+		}
+		if _, err := prompt.Run(); err == nil {
+			if err := hosts.SaveWithSudo(save); err == nil {
+				fmt.Println("Hosts file updated!")
+				fmt.Println("Previous hosts file backed up to: " + save.BackupFile)
+				return
+			}
+			fmt.Println("Writing with sudo failed.")
+		}
+	}
+	fmt.Println("You can rerun as an administrator.")
+	fmt.Println("Or edit the hosts file yourself.")
+	fmt.Println("Temporary file: " + save.TmpFile)
+	fmt.Println("")
+	fmt.Println(save.Content)
+}
+
 var mwddHostsAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Adds development environment hosts into your system hosts file (might need sudo)",
 	Run: func(cmd *cobra.Command, args []string) {
-		save := hosts.AddHosts(
-			append(
-				[]string{
-					// TODO generate these by reading the yml files?
-					"proxy.mwdd.localhost",
-					"adminer.mwdd.localhost",
-					"graphite.mwdd.localhost",
-					"phpmyadmin.mwdd.localhost",
-					"default.mediawiki.mwdd.localhost",
-				},
-				mwdd.DefaultForUser().UsedHosts()...,
-			),
-		)
-		if save.Success {
-			fmt.Println("Hosts file updated!")
-		} else {
-			fmt.Println("Could not save your hosts file.")
-			fmt.Println("You can return with sudo.")
-			fmt.Println("Or edit the hosts fiel yourself.")
-			fmt.Println("Temporary file: " + save.TmpFile)
-			fmt.Println("")
-			fmt.Println(save.Content)
+		mwddHostsPrintSave(hosts.AddHosts(mwdd.DefaultForUser().Hosts()))
+	},
+}
+
+var mwddHostsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the development environment hosts that would be added to your system hosts file",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, host := range mwdd.DefaultForUser().Hosts() {
+			fmt.Println("127.0.0.1 " + host)
 		}
 	},
 }
 
+var mwddHostsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Adds missing and removes stale development environment hosts in your system hosts file (might need sudo)",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwddHostsPrintSave(hosts.SyncHosts(mwdd.DefaultForUser().Hosts()))
+	},
+}
+
 var mwddHostsRemoveCmd = &cobra.Command{
 	Use:   "remove",
 	Short: "Removes development environment hosts from your system hosts file (might need sudo)",
 	Run: func(cmd *cobra.Command, args []string) {
-		save := hosts.RemoveHostsWithSuffix("mwdd.localhost")
-		if save.Success {
-			fmt.Println("Hosts file updated!")
-		} else {
-			fmt.Println("Could not save your hosts file.")
-			fmt.Println("You can return with sudo.")
-			fmt.Println("Or edit the hosts fiel yourself.")
-			fmt.Println("Temporary file: " + save.TmpFile)
-			fmt.Println("")
-			fmt.Println(save.Content)
-		}
+		mwddHostsPrintSave(hosts.RemoveHosts())
 	},
 }
 
 func init() {
 	mwddCmd.AddCommand(mwddHostsCmd)
 	mwddHostsCmd.AddCommand(mwddHostsAddCmd)
+	mwddHostsCmd.AddCommand(mwddHostsShowCmd)
+	mwddHostsCmd.AddCommand(mwddHostsSyncCmd)
 	mwddHostsCmd.AddCommand(mwddHostsRemoveCmd)
 }
@@ -0,0 +1,139 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/ports"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var mwddHTTPSCmd = &cobra.Command{
+	Use:   "https",
+	Short: "Serve the development environment over https using a locally generated CA",
+	RunE:  nil,
+}
+
+// Services that need recreating for https settings to apply, if they are running
+var mwddHTTPSServices = []string{"nginx-proxy", "mediawiki", "mediawiki-web", "adminer", "phpmyadmin", "graphite"}
+
+func mwddHTTPSRecreateRunningServices() {
+	toRecreate := []string{}
+	for _, service := range mwddHTTPSServices {
+		if mwdd.DefaultForUser().ServiceIsRunning(service) {
+			toRecreate = append(toRecreate, service)
+		}
+	}
+	if len(toRecreate) > 0 {
+		mwdd.DefaultForUser().Recreate(toRecreate, exec.HandlerOptions{
+			Verbosity: Verbosity,
+		})
+	}
+}
+
+func mwddHTTPSPrintTrustInstructions(caPath string) {
+	fmt.Println("Your browser and system need to trust the local certificate authority:")
+	fmt.Println("  " + caPath)
+	fmt.Println("")
+	switch runtime.GOOS {
+	case "darwin":
+		fmt.Println("  sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain " + caPath)
+	case "windows":
+		fmt.Println("  certutil -addstore -f \"ROOT\" " + caPath)
+	default:
+		fmt.Println("  Debian / Ubuntu: sudo cp " + caPath + " /usr/local/share/ca-certificates/mwcli-mwdd.crt && sudo update-ca-certificates")
+		fmt.Println("  Fedora / RHEL:   sudo cp " + caPath + " /etc/pki/ca-trust/source/anchors/mwcli-mwdd.crt && sudo update-ca-trust")
+	}
+	fmt.Println("")
+	fmt.Println("Firefox keeps its own list, import the file in Settings > Privacy & Security > Certificates > View Certificates > Authorities.")
+}
+
+var mwddHTTPSEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Generate certificates and serve the environment over https",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+
+		if mwdd.DefaultForUser().Env().Missing("HTTPS_PORT") {
+			httpPort := mwdd.DefaultForUser().Env().Get("PORT")
+			defaultPort := ""
+			free, err := ports.FreeSet(ports.AllInterfaces, []ports.Range{ports.RangeFrom("8443")}, []string{httpPort})
+			if err != nil {
+				fmt.Println("Could not find a free port to suggest: " + err.Error())
+			} else {
+				defaultPort = free[0]
+			}
+			prompt := promptui.Prompt{
+				Label:   "What port would you like to use for https?",
+				Default: defaultPort,
+				Validate: func(port string) error {
+					if port == httpPort {
+						return errors.New("port is already used for http")
+					}
+					return ports.IsValidAndFree(port)
+				},
+			}
+			value, err := prompt.Run()
+			if err != nil {
+				fmt.Println("Can't continue without an https port")
+				os.Exit(1)
+			}
+			mwdd.DefaultForUser().Env().Set("HTTPS_PORT", value)
+		}
+
+		if err := mwdd.DefaultForUser().EnableHTTPS(); err != nil {
+			fmt.Println("Failed to enable https:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		mwddHTTPSRecreateRunningServices()
+
+		fmt.Println("")
+		fmt.Println("https enabled on port " + mwdd.DefaultForUser().Env().Get("HTTPS_PORT"))
+		fmt.Println("")
+		mwddHTTPSPrintTrustInstructions(mwdd.DefaultForUser().CACertificatePath())
+	},
+}
+
+var mwddHTTPSDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop serving the environment over https",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if err := mwdd.DefaultForUser().DisableHTTPS(); err != nil {
+			fmt.Println("Failed to disable https:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		mwddHTTPSRecreateRunningServices()
+		fmt.Println("https disabled")
+	},
+}
+
+func init() {
+	mwddCmd.AddCommand(mwddHTTPSCmd)
+	mwddHTTPSCmd.AddCommand(mwddHTTPSEnableCmd)
+	mwddHTTPSCmd.AddCommand(mwddHTTPSDisableCmd)
+}
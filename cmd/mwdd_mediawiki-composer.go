@@ -0,0 +1,180 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mediawiki"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*ComposerUpdateAllJobs used by the composer-update-all command*/
+var ComposerUpdateAllJobs int
+
+type mwddComposerUpdateResult struct {
+	directory string
+	output    string
+	err       error
+	duration  time.Duration
+}
+
+// mwddComposerDirectoryName core is the empty directory, so name it for output
+func mwddComposerDirectoryName(directory string) string {
+	if directory == "" {
+		return "core"
+	}
+	return directory
+}
+
+func mwddComposerUpdate(directory string) mwddComposerUpdateResult {
+	start := time.Now()
+	output, err := mwdd.DefaultForUser().ExecWithOutput("mediawiki", []string{
+		"sh", "-c", "cd " + mwdd.ShellQuote(strings.TrimSuffix("/var/www/html/w/"+directory, "/")) + " && composer update --no-interaction --ignore-platform-reqs",
+	}, User)
+	return mwddComposerUpdateResult{
+		directory: directory,
+		output:    output,
+		err:       err,
+		duration:  time.Since(start),
+	}
+}
+
+var mwddMediawikiComposerStatusCmd = &cobra.Command{
+	Use:   "composer-status",
+	Short: "Shows the composer state of MediaWiki core and every extension and skin with a composer.json",
+	Long: `Shows the composer state of MediaWiki core and every extension and skin with a composer.json.
+
+A directory is outdated when a package that its composer.json requires is missing from its vendor directory.
+Core is also checked using checkComposerLockUpToDate.php, when the mediawiki service is running.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		code := mwddMediaWikiCode()
+
+		outdated := false
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DIRECTORY\tSTATUS\tMISSING")
+		for _, directory := range code.ComposerDirectories() {
+			state := code.ComposerState(directory)
+			if directory == "" && state.Status == mediawiki.ComposerUpToDate && mwdd.DefaultForUser().ServiceIsRunning("mediawiki") && mwddComposerCheck() != nil {
+				state.Status = mediawiki.ComposerOutdated
+			}
+			if state.Status != mediawiki.ComposerUpToDate {
+				outdated = true
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", mwddComposerDirectoryName(directory), state.Status, strings.Join(state.Missing, ", "))
+		}
+		w.Flush()
+
+		if outdated {
+			fmt.Println("Run composer-update-all to update every directory")
+		}
+	},
+}
+
+var mwddMediawikiComposerUpdateAllCmd = &cobra.Command{
+	Use:   "composer-update-all",
+	Short: "Runs composer update for MediaWiki core and every extension and skin with a composer.json, in parallel",
+	Example: `  composer-update-all
+  composer-update-all --jobs 8`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if ComposerUpdateAllJobs < 1 {
+			fmt.Println("--jobs must be at least 1")
+			os.Exit(1)
+		}
+
+		directories := mwddMediaWikiCode().ComposerDirectories()
+		fmt.Printf("Running composer update in %d directories\n", len(directories))
+
+		results := make([]mwddComposerUpdateResult, len(directories))
+		jobs := make(chan struct{}, ComposerUpdateAllJobs)
+		var wg sync.WaitGroup
+		for i, directory := range directories {
+			wg.Add(1)
+			go func(i int, directory string) {
+				defer wg.Done()
+				jobs <- struct{}{}
+				defer func() { <-jobs }()
+				results[i] = mwddComposerUpdate(directory)
+			}(i, directory)
+		}
+		wg.Wait()
+
+		failed := false
+		for _, result := range results {
+			if result.err != nil || Verbosity >= 2 {
+				fmt.Println("*** composer update output for " + mwddComposerDirectoryName(result.directory))
+				fmt.Println(result.output)
+			}
+			if result.err != nil {
+				fmt.Println(result.err)
+				failed = true
+			}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DIRECTORY\tRESULT\tDURATION")
+		for _, result := range results {
+			status := "ok"
+			if result.err != nil {
+				status = "failed"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", mwddComposerDirectoryName(result.directory), status, result.duration.Round(time.Second))
+		}
+		w.Flush()
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var mwddMediawikiCleanCmd = &cobra.Command{
+	Use:       "clean [vendor|cache]",
+	Short:     "Empties the vendor or cache directory of MediaWiki",
+	Example:   "  clean vendor\n  clean cache",
+	ValidArgs: []string{"vendor", "cache"},
+	Args:      cobra.ExactValidArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		code := mwddMediaWikiCode()
+		switch args[0] {
+		case "vendor":
+			code.DeleteVendor()
+		case "cache":
+			code.DeleteCache()
+		}
+		fmt.Println("Emptied " + code.Path(args[0]))
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiComposerStatusCmd)
+	mwddMediawikiCmd.AddCommand(mwddMediawikiComposerUpdateAllCmd)
+	mwddMediawikiCmd.AddCommand(mwddMediawikiCleanCmd)
+	mwddMediawikiComposerStatusCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	mwddMediawikiComposerUpdateAllCmd.Flags().IntVarP(&ComposerUpdateAllJobs, "jobs", "j", 4, "Number of composer updates to run at once")
+	mwddMediawikiComposerUpdateAllCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+}
@@ -0,0 +1,122 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*FarmSharedDB used by the farm sync command*/
+var FarmSharedDB string
+
+/*FarmNoSharedDB used by the farm sync command*/
+var FarmNoSharedDB bool
+
+var mwddMediawikiFarmCmd = &cobra.Command{
+	Use:   "farm",
+	Short: "Configures the installed wikis as a farm that knows about each other",
+	Long: `Configures the installed wikis as a farm that knows about each other.
+
+The farm is configured in settings.d/all/Farm.php, which sets $wgLocalDatabases and $wgConf,
+adds interwiki prefixes for each wiki, and can share user tables between mysql wikis.
+This allows extensions such as CentralAuth, GlobalBlocking and Wikibase to be tested locally.`,
+	RunE: nil,
+}
+
+var mwddMediawikiFarmSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Writes the farm settings for all installed wikis, they are then kept up to date by install and destroy",
+	Example: `  farm sync
+  farm sync --shared-db default
+  farm sync --no-shared-db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if FarmSharedDB != "" && FarmNoSharedDB {
+			fmt.Println("Use either --shared-db or --no-shared-db, not both")
+			os.Exit(1)
+		}
+		if FarmSharedDB != "" || FarmNoSharedDB {
+			if err := mwdd.DefaultForUser().SetFarmSharedDB(FarmSharedDB); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		if err := mwdd.DefaultForUser().SyncFarm(); err != nil {
+			fmt.Println("Failed to write the farm settings:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Farm settings written to " + mwdd.DefaultForUser().FarmSettingsFile())
+		mwddMediawikiFarmPrint()
+	},
+}
+
+func mwddMediawikiFarmPrint() {
+	records := mwdd.DefaultForUser().WikiRecords()
+	wikis := []string{}
+	for wiki := range records {
+		wikis = append(wikis, wiki)
+	}
+	sort.Strings(wikis)
+	for _, wiki := range wikis {
+		fmt.Printf("  %s (%s), interwiki prefix %s\n", wiki, records[wiki].Type, strings.ToLower(wiki))
+	}
+	if shared := mwdd.DefaultForUser().FarmSharedDB(); shared != "" {
+		fmt.Println("mysql wikis share user tables with " + shared)
+	}
+}
+
+var mwddMediawikiFarmShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the wikis of the farm",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if !mwdd.DefaultForUser().FarmEnabled() {
+			fmt.Println("The farm is not configured, use `farm sync` to configure it")
+			return
+		}
+		mwddMediawikiFarmPrint()
+	},
+}
+
+var mwddMediawikiFarmRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Removes the farm settings, so that wikis no longer know about each other",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if err := mwdd.DefaultForUser().RemoveFarm(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiFarmCmd)
+	mwddMediawikiFarmCmd.AddCommand(mwddMediawikiFarmSyncCmd)
+	mwddMediawikiFarmCmd.AddCommand(mwddMediawikiFarmShowCmd)
+	mwddMediawikiFarmCmd.AddCommand(mwddMediawikiFarmRemoveCmd)
+	mwddMediawikiFarmSyncCmd.Flags().StringVarP(&FarmSharedDB, "shared-db", "", "", "mysql wiki to share user tables with, as $wgSharedDB of the other mysql wikis")
+	mwddMediawikiFarmSyncCmd.Flags().BoolVarP(&FarmNoSharedDB, "no-shared-db", "", false, "Stop sharing user tables")
+	mwddMediawikiFarmSyncCmd.RegisterFlagCompletionFunc("shared-db", mwddWikiNameFlagCompletion)
+}
@@ -0,0 +1,154 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/archive"
+	"github.com/spf13/cobra"
+)
+
+/*ExportPages used by the export command*/
+var ExportPages []string
+
+/*ExportCurrent used by the export command*/
+var ExportCurrent bool
+
+// mwddImportScript runs a maintenance script for a wiki, exiting if it fails
+func mwddImportScript(wiki string, script string, scriptArgs ...string) {
+	command := append([]string{"php", "/var/www/html/w/maintenance/" + script, "--wiki", mwdd.ShellQuote(wiki)}, scriptArgs...)
+	if code := mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              command,
+		User:                 User,
+	}); code != 0 {
+		fmt.Println(script + " failed")
+		os.Exit(1)
+	}
+}
+
+// mwddStreamExec runs a command without a TTY, streaming stdin and stdout, and exits if it fails
+func mwddStreamExec(command string, stdin io.Reader, stdout io.Writer) {
+	if code := mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              []string{command},
+		User:                 User,
+		NoTTY:                true,
+		Stdin:                stdin,
+		Stdout:               stdout,
+	}); code != 0 {
+		fmt.Fprintf(os.Stderr, "Command failed with exit code %d\n", code)
+		os.Exit(1)
+	}
+}
+
+var mwddMediawikiImportCmd = &cobra.Command{
+	Use:               "import <wiki> <file.xml[.gz|.bz2]>",
+	Short:             "Imports an XML dump into a wiki, streaming it into the container",
+	Example:           "  import default pages.xml\n  import otherwiki enwiki-latest-pages-articles1.xml.bz2",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: mwddWikiNameThenFileCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		wiki := args[0]
+		dump, err := archive.OpenDecompressed(args[1])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer dump.Close()
+
+		fmt.Println("Importing " + args[1] + " into " + wiki)
+		// importDump.php reads from stdin when not given a file
+		mwddStreamExec("php /var/www/html/w/maintenance/importDump.php --wiki "+mwdd.ShellQuote(wiki), dump, os.Stdout)
+		mwddImportScript(wiki, "rebuildrecentchanges.php")
+		mwddImportScript(wiki, "initSiteStats.php", "--update")
+	},
+}
+
+var mwddMediawikiImportImagesCmd = &cobra.Command{
+	Use:               "import-images <wiki> <directory> [-- importImages.php arguments...]",
+	Short:             "Imports the images in a directory into a wiki, streaming them into the container",
+	Example:           "  import-images default ~/Pictures/wiki\n  import-images default ./images -- --comment='Seed images'",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: mwddWikiNameThenFileCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		wiki := args[0]
+		if info, err := os.Stat(args[1]); err != nil || !info.IsDir() {
+			fmt.Println(args[1] + " is not a directory")
+			os.Exit(1)
+		}
+
+		reader, writer := io.Pipe()
+		go func() {
+			writer.CloseWithError(archive.TarDirectory(args[1], writer))
+		}()
+
+		scriptArgs := []string{}
+		for _, arg := range args[2:] {
+			scriptArgs = append(scriptArgs, mwdd.ShellQuote(arg))
+		}
+		// Extract into a temporary directory of the container, so that nothing is written to the code mount
+		command := `dir=$(mktemp -d) && tar -x -C "$dir" && ` +
+			"php /var/www/html/w/maintenance/importImages.php --wiki " + mwdd.ShellQuote(wiki) + " --search-recursively " + strings.Join(scriptArgs, " ") + ` "$dir"; ` +
+			`status=$?; rm -rf "$dir"; exit $status`
+		fmt.Println("Importing images from " + args[1] + " into " + wiki)
+		mwddStreamExec(command, reader, os.Stdout)
+	},
+}
+
+var mwddMediawikiExportCmd = &cobra.Command{
+	Use:               "export <wiki> [--pages page,...] > out.xml",
+	Short:             "Exports an XML dump of a wiki to stdout, using dumpBackup.php",
+	Example:           "  export default > default.xml\n  export default --current --pages Main_Page,Help:Contents > pages.xml",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: mwddWikiNameCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		command := "php /var/www/html/w/maintenance/dumpBackup.php --wiki " + mwdd.ShellQuote(args[0])
+		if ExportCurrent {
+			command += " --current"
+		} else {
+			command += " --full"
+		}
+		pageList := ""
+		if len(ExportPages) > 0 {
+			command += " --pagelist=/dev/stdin"
+			pageList = strings.Join(ExportPages, "\n") + "\n"
+		}
+		// Only the dump is written to stdout, progress is reported by dumpBackup.php on stderr
+		mwddStreamExec(command, strings.NewReader(pageList), os.Stdout)
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiImportCmd)
+	mwddMediawikiCmd.AddCommand(mwddMediawikiImportImagesCmd)
+	mwddMediawikiCmd.AddCommand(mwddMediawikiExportCmd)
+	for _, command := range []*cobra.Command{mwddMediawikiImportCmd, mwddMediawikiImportImagesCmd, mwddMediawikiExportCmd} {
+		command.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	}
+	mwddMediawikiExportCmd.Flags().StringSliceVarP(&ExportPages, "pages", "", []string{}, "Titles of the pages to export, defaults to all pages")
+	mwddMediawikiExportCmd.Flags().BoolVarP(&ExportCurrent, "current", "", false, "Export only the current revision of each page, rather than the full history")
+}
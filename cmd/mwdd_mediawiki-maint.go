@@ -0,0 +1,144 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mediawiki"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*MaintWiki used by the maint command*/
+var MaintWiki string
+
+/*MaintAllWikis used by the maint command*/
+var MaintAllWikis bool
+
+func mwddMediaWikiCode() mediawiki.MediaWiki {
+	mw, _ := mediawiki.ForDirectory(mwdd.DefaultForUser().Env().Get("MEDIAWIKI_VOLUMES_CODE"))
+	return mw
+}
+
+// mwddMaintExtensions the extensions that LocalSettings.php and the settings.d files load, for the wiki or for any wiki
+func mwddMaintExtensions(wiki string) []string {
+	settingsFiles := []string{mwddMediaWikiCode().Path("LocalSettings.php")}
+	if wiki != "" {
+		settingsFiles = append(settingsFiles, mwdd.DefaultForUser().SettingsFiles(wiki)...)
+	} else {
+		settingsFiles = append(settingsFiles, mwdd.DefaultForUser().SettingsFiles(mwdd.SettingsForAllWikis)...)
+		for _, settingsWiki := range mwdd.DefaultForUser().SettingsWikis() {
+			settingsFiles = append(settingsFiles, mwdd.DefaultForUser().SettingsFiles(settingsWiki)...)
+		}
+	}
+	return mediawiki.LoadedExtensions(settingsFiles)
+}
+
+func mwddMaintScriptCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return mwddMediaWikiCode().MaintenanceScripts(mwddMaintExtensions(MaintWiki)), cobra.ShellCompDirectiveNoFileComp
+}
+
+func mwddMaintRun(scriptPath string, wiki string, scriptArgs []string) int {
+	command := []string{"php", "/var/www/html/w/" + scriptPath, "--wiki", mwdd.ShellQuote(wiki)}
+	for _, arg := range scriptArgs {
+		command = append(command, mwdd.ShellQuote(arg))
+	}
+	return mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              command,
+		User:                 User,
+	})
+}
+
+var mwddMediawikiMaintCmd = &cobra.Command{
+	Use:   "maint <script> [flags] [-- script arguments...]",
+	Short: "Runs a MediaWiki maintenance script for one wiki, or all wikis",
+	Long: `Runs a MediaWiki maintenance script for one wiki, or all wikis.
+
+Scripts in the maintenance directory of MediaWiki core are named as they are, such as runJobs.
+Scripts of loaded extensions are prefixed with the extension name, such as Echo:updatePerUserBlacklist.`,
+	Example: `  maint showJobs
+  maint runJobs --wiki otherwiki
+  maint update --all-wikis -- --quick
+  maint Echo:updatePerUserBlacklist --wiki default`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: mwddMaintScriptCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if MaintAllWikis && MaintWiki != "" {
+			fmt.Println("Use either --wiki or --all-wikis, not both")
+			os.Exit(1)
+		}
+
+		scriptPath, err := mwddMediaWikiCode().MaintenanceScriptPath(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if !MaintAllWikis {
+			wiki := MaintWiki
+			if wiki == "" {
+				wiki = "default"
+			}
+			os.Exit(mwddMaintRun(scriptPath, wiki, args[1:]))
+		}
+
+		wikis := mwdd.DefaultForUser().UsedDBNames()
+		if len(wikis) == 0 {
+			fmt.Println("No wikis are installed")
+			os.Exit(1)
+		}
+		failed := []string{}
+		results := map[string]int{}
+		for _, wiki := range wikis {
+			fmt.Println("*** Running " + args[0] + " for " + wiki)
+			results[wiki] = mwddMaintRun(scriptPath, wiki, args[1:])
+			if results[wiki] != 0 {
+				failed = append(failed, wiki)
+			}
+		}
+
+		fmt.Println("***************************************")
+		for _, wiki := range wikis {
+			if results[wiki] == 0 {
+				fmt.Printf("PASS %s\n", wiki)
+			} else {
+				fmt.Printf("FAIL %s (exit code %d)\n", wiki, results[wiki])
+			}
+		}
+		if len(failed) > 0 {
+			fmt.Println("Failed for: " + strings.Join(failed, ", "))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiMaintCmd)
+	mwddMediawikiMaintCmd.Flags().StringVarP(&MaintWiki, "wiki", "w", "", "Wiki database name to run the script for, defaults to default")
+	mwddMediawikiMaintCmd.Flags().BoolVarP(&MaintAllWikis, "all-wikis", "", false, "Run the script for every installed wiki in turn")
+	mwddMediawikiMaintCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	mwddMediawikiMaintCmd.RegisterFlagCompletionFunc("wiki", mwddWikiNameFlagCompletion)
+}
@@ -0,0 +1,222 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mediawiki"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*PresetDbType used by the preset command*/
+var PresetDbType string
+
+/*PresetShallow used by the preset command*/
+var PresetShallow bool
+
+/*PresetList used by the preset command*/
+var PresetList bool
+
+func mwddPresetList() {
+	for _, name := range mwdd.DefaultForUser().Presets() {
+		preset, err := mwdd.DefaultForUser().Preset(name)
+		if err != nil {
+			fmt.Println(name + ": " + err.Error())
+			continue
+		}
+		fmt.Println(name + ": " + preset.Description)
+	}
+}
+
+func mwddPresetCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return mwdd.DefaultForUser().Presets(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// mwddPresetComposer merges the composer.json files of the preset repositories, and updates composer if needed
+func mwddPresetComposer(preset mwdd.Preset) {
+	code := mwddMediaWikiCode()
+	merged := false
+	for _, repository := range preset.Repositories {
+		if !repository.ComposerMerge {
+			continue
+		}
+		added, err := code.AddComposerLocalInclude(repository.Path + "/composer.json")
+		if err != nil {
+			fmt.Println("Failed to update composer.local.json:", err)
+			os.Exit(1)
+		}
+		merged = merged || added
+	}
+	mwddQuickstartStep("Update composer dependencies", !merged && mwddComposerCheck() == nil, func() {
+		if mwddComposer("", "update") != 0 {
+			fmt.Println("composer update failed")
+			os.Exit(1)
+		}
+	})
+}
+
+// mwddPresetScripts runs the scripts of a wiki that have not run yet, recording each one that succeeds so that a failed script is retried on the next apply
+func mwddPresetScripts(presetName string, wiki mwdd.PresetWiki) error {
+	m := mwdd.DefaultForUser()
+	for i := m.PresetScriptsDone(presetName, wiki.Name); i < len(wiki.Scripts); i++ {
+		script := wiki.Scripts[i]
+		command := []string{"php", "/var/www/html/w/" + script[0], "--wiki", mwdd.ShellQuote(wiki.Name)}
+		for _, arg := range script[1:] {
+			command = append(command, mwdd.ShellQuote(m.ExpandPresetPlaceholders(arg)))
+		}
+		if m.DockerExec(mwdd.DockerExecCommand{
+			DockerComposeService: "mediawiki",
+			Command:              command,
+			User:                 User,
+		}) != 0 {
+			return fmt.Errorf("%s failed for %s, apply the preset again to retry", script[0], wiki.Name)
+		}
+		if err := m.RecordPresetScriptsDone(presetName, wiki.Name, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var mwddMediawikiPresetCmd = &cobra.Command{
+	Use:   "preset <name>",
+	Short: "Sets up a preset stack of extensions and wikis, skipping any steps that are already done",
+	Long: `Sets up a preset stack of extensions and wikis, skipping any steps that are already done.
+
+Presets are JSON files in the presets directory of the environment, listing the repositories to clone,
+the wikis to install, the settings to write to settings.d for each wiki, and maintenance scripts to run
+once each wiki is installed. Apply a preset again after changing the port, as settings include URLs.`,
+	Example:           "  preset --list\n  preset wikibase",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: mwddPresetCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if PresetList || len(args) == 0 {
+			mwddPresetList()
+			return
+		}
+
+		name := args[0]
+		m := mwdd.DefaultForUser()
+		preset, err := m.Preset(name)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		supported := preset.DBTypes
+		if len(supported) == 0 {
+			supported = []string{"mysql", "postgres", "sqlite"}
+		}
+		dbType := PresetDbType
+		if dbType == "" {
+			dbType = supported[0]
+		}
+		if !mwddStringInSlice(dbType, supported) {
+			fmt.Println("The " + name + " preset needs one of these dbtypes: " + strings.Join(supported, ", "))
+			os.Exit(1)
+		}
+
+		code := mwddMediaWikiCode()
+		for _, repository := range preset.Repositories {
+			repository := repository
+			mwddQuickstartStep("Clone "+repository.Project, code.ProjectIsPresent(repository.Path), func() {
+				err := code.CloneProject(repository.Project, repository.Path, repository.Submodules, mediawiki.CloneSetupOpts{
+					UseShallow:            PresetShallow,
+					GerritInteractionType: "http",
+					Options: exec.HandlerOptions{
+						Verbosity: Verbosity,
+					},
+				})
+				if err != nil {
+					fmt.Println("Failed to clone " + repository.Project + ": " + err.Error())
+					os.Exit(1)
+				}
+			})
+		}
+
+		mwddQuickstartServices(dbType)
+		mwddPresetComposer(preset)
+
+		for _, wiki := range preset.Wikis {
+			if err := m.WritePresetSettings(name, wiki); err != nil {
+				fmt.Println("Failed to write settings for " + wiki.Name + ": " + err.Error())
+				os.Exit(1)
+			}
+		}
+		if preset.Farm {
+			if err := m.SyncFarm(); err != nil {
+				fmt.Println("Failed to write the farm settings:", err)
+				os.Exit(1)
+			}
+		}
+
+		for _, wiki := range preset.Wikis {
+			wiki := wiki
+			_, done := m.WikiRecords()[wiki.Name]
+			mwddQuickstartStep("Install the "+wiki.Name+" wiki", done, func() {
+				if err := mwddMediawikiInstall(dbType, wiki.Name); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			})
+		}
+		// Scripts can refer to any wiki of the preset, so they only run once all are installed
+		for _, wiki := range preset.Wikis {
+			wiki := wiki
+			done := m.PresetScriptsDone(name, wiki.Name) >= len(wiki.Scripts)
+			mwddQuickstartStep("Run the scripts for the "+wiki.Name+" wiki", done, func() {
+				if err := mwddPresetScripts(name, wiki); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			})
+		}
+
+		fmt.Println("")
+		fmt.Println("User: " + mwddAdminUser)
+		fmt.Println("Pass: " + mwddAdminPassword)
+		for _, wiki := range preset.Wikis {
+			fmt.Println(wiki.Name + ": " + m.HostURL(wiki.Name+".mediawiki."+mwdd.HostSuffix))
+		}
+	},
+}
+
+func mwddStringInSlice(value string, slice []string) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiPresetCmd)
+	mwddMediawikiPresetCmd.Flags().StringVarP(&PresetDbType, "dbtype", "", "", "Type of database to install, defaults to the first supported by the preset")
+	mwddMediawikiPresetCmd.Flags().BoolVarP(&PresetShallow, "shallow", "", false, "Use shallow clones for extra speed")
+	mwddMediawikiPresetCmd.Flags().BoolVarP(&PresetList, "list", "", false, "List the presets")
+	mwddMediawikiPresetCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+}
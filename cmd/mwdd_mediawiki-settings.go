@@ -0,0 +1,127 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*SettingsWiki used by the settings commands*/
+var SettingsWiki string
+
+var mwddMediawikiSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage extra PHP settings files loaded by MediaWiki, for all wikis or a single wiki",
+	RunE:  nil,
+}
+
+func mwddEditor() []string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return strings.Fields(editor)
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+var mwddMediawikiSettingsEditCmd = &cobra.Command{
+	Use:     "edit [file]",
+	Short:   "Opens a settings file in $EDITOR, creating it if needed",
+	Example: "  edit\n  edit --wiki default\n  edit --wiki default debug.php",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		file := ""
+		if len(args) == 1 {
+			file = args[0]
+		}
+		path, err := mwdd.DefaultForUser().SettingsFile(SettingsWiki, file)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if err := ioutil.WriteFile(path, []byte("<?php\n\n"), 0644); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		editor := mwddEditor()
+		exec.RunTTYCommand(
+			exec.HandlerOptions{Verbosity: Verbosity},
+			exec.Command(editor[0], append(editor[1:], path)...),
+		)
+	},
+}
+
+var mwddMediawikiSettingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the settings files that apply to each wiki, in the order that they are loaded",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		candidates := append(mwdd.DefaultForUser().UsedDBNames(), mwdd.DefaultForUser().SettingsWikis()...)
+		if SettingsWiki != "" {
+			candidates = []string{SettingsWiki}
+		}
+		sort.Strings(candidates)
+		wikis := []string{mwdd.SettingsForAllWikis}
+		for _, wiki := range candidates {
+			if wiki != wikis[len(wikis)-1] && wiki != mwdd.SettingsForAllWikis {
+				wikis = append(wikis, wiki)
+			}
+		}
+
+		fmt.Println("Settings directory: " + mwdd.DefaultForUser().SettingsDirectory())
+		for _, wiki := range wikis {
+			fmt.Println("")
+			fmt.Println(wiki + ":")
+			files := mwdd.DefaultForUser().SettingsFiles(wiki)
+			if len(files) == 0 {
+				fmt.Println("  (none)")
+			}
+			for _, file := range files {
+				relative, _ := filepath.Rel(mwdd.DefaultForUser().SettingsDirectory(), file)
+				fmt.Println("  " + relative)
+			}
+		}
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiSettingsCmd)
+	mwddMediawikiSettingsCmd.AddCommand(mwddMediawikiSettingsEditCmd)
+	mwddMediawikiSettingsCmd.AddCommand(mwddMediawikiSettingsListCmd)
+	mwddMediawikiSettingsCmd.PersistentFlags().StringVarP(&SettingsWiki, "wiki", "w", "", "Wiki database name, defaults to settings for all wikis")
+	mwddMediawikiSettingsCmd.RegisterFlagCompletionFunc("wiki", mwddWikiNameFlagCompletion)
+}
@@ -0,0 +1,223 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*TestDB used by the test commands*/
+var TestDB string
+
+/*TestSuite used by the test phpunit command*/
+var TestSuite string
+
+/*TestGroup used by the test phpunit command*/
+var TestGroup string
+
+/*TestFilter used by the test phpunit and parser commands*/
+var TestFilter string
+
+// mwddTestSuiteForPaths tests under a unit directory run without MediaWiki set up, everything else is an integration test
+func mwddTestSuiteForPaths(paths []string) string {
+	if len(paths) == 0 {
+		return "unit"
+	}
+	for _, path := range paths {
+		slashed := "/" + strings.Trim(filepath.ToSlash(path), "/") + "/"
+		if !strings.Contains(slashed, "/unit/") {
+			return "integration"
+		}
+	}
+	return "unit"
+}
+
+// mwddTestResultsFile the container path of a results file, printing where it will be on the host
+func mwddTestResultsFile(name string) string {
+	fmt.Println("JUnit results will be written to " + filepath.Join(mwdd.DefaultForUser().TestResultsDirectory(), name))
+	return mwdd.TestResultsContainerDirectory + "/" + name
+}
+
+func mwddTestPHPUnitFlags() []string {
+	flags := []string{}
+	if TestGroup != "" {
+		flags = append(flags, "--group", mwdd.ShellQuote(TestGroup))
+	}
+	if TestFilter != "" {
+		flags = append(flags, "--filter", mwdd.ShellQuote(TestFilter))
+	}
+	return flags
+}
+
+// mwddTestCheckDB checks that MediaWiki picks the database of --db from MW_DB, so tests never run against another wiki
+func mwddTestCheckDB() error {
+	output, err := mwdd.DefaultForUser().ExecWithOutput("mediawiki", []string{
+		"sh", "-c", "MW_DB=" + mwdd.ShellQuote(TestDB) + " php /var/www/html/w/maintenance/getConfiguration.php --settings wgDBname --format json",
+	}, User)
+	if err != nil {
+		return fmt.Errorf("unable to load the configuration of %s: %v", TestDB, err)
+	}
+	settings := map[string]string{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &settings); err != nil {
+		return fmt.Errorf("unable to read the configuration of %s: %v", TestDB, err)
+	}
+	if settings["wgDBname"] != TestDB {
+		return fmt.Errorf("MediaWiki uses the database %s instead of %s", settings["wgDBname"], TestDB)
+	}
+	return nil
+}
+
+// mwddTestExec runs a test command in the mediawiki container, checking the database of --db is used when it needs one
+func mwddTestExec(command []string, usesDB bool) {
+	mwdd.DefaultForUser().EnsureReady()
+	if usesDB {
+		if err := mwddTestCheckDB(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(mwdd.DefaultForUser().DockerExec(applyRelevantWorkingDirectory(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              append([]string{"MW_DB=" + mwdd.ShellQuote(TestDB)}, command...),
+		User:                 User,
+	})))
+}
+
+// mwddTestBrowser runs an npm script of MediaWiki core in the mediawiki-browser container, against the wiki of --db
+func mwddTestBrowser(npmScript string, resultsDirectory string, args []string) {
+	mwdd.DefaultForUser().EnsureReady()
+	environment := []string{
+		// Inside the docker network the proxy serves http on PORT, and HTTPS uses a CA the browser container doesn't trust
+		"MW_SERVER=" + mwdd.ShellQuote("http://"+TestDB+".mediawiki."+mwdd.HostSuffix+":"+mwdd.DefaultForUser().Env().Get("PORT")),
+		"MEDIAWIKI_USER=" + mwddAdminUser,
+		"MEDIAWIKI_PASSWORD=" + mwddAdminPassword,
+		"LOG_DIR=" + mwddTestResultsFile(resultsDirectory),
+	}
+	npm := []string{"npm", "run", npmScript}
+	if len(args) > 0 {
+		npm = append(npm, "--")
+		for _, arg := range args {
+			npm = append(npm, mwdd.ShellQuote(arg))
+		}
+	}
+	script := "( [ -d node_modules ] || npm ci ) && " + strings.Join(environment, " ") + " " + strings.Join(npm, " ")
+	mwdd.DefaultForUser().RunAs("mediawiki-browser", User, []string{"sh", "-c", script}, exec.HandlerOptions{
+		Verbosity: Verbosity,
+	})
+}
+
+var mwddMediawikiTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Runs the tests of MediaWiki, writing JUnit XML results to the test-results directory",
+}
+
+var mwddMediawikiTestPHPUnitCmd = &cobra.Command{
+	Use:   "phpunit [paths...] [flags] [-- phpunit arguments...]",
+	Short: "Runs PHPUnit tests, relative to the current directory when inside MediaWiki",
+	Long: `Runs PHPUnit tests, relative to the current directory when inside MediaWiki.
+
+Paths within a unit directory are run as unit tests, others as integration tests using --db.
+With no paths the core unit test suite is run, use --suite integration for the integration suite.`,
+	Example: `  test phpunit
+  test phpunit tests/phpunit/unit/includes/XmlTest.php
+  test phpunit --suite integration --group Database
+  test phpunit tests/phpunit/includes/TitleTest.php --filter testSecureAndSplit --db otherwiki`,
+	Run: func(cmd *cobra.Command, args []string) {
+		suite := TestSuite
+		if suite == "" {
+			suite = mwddTestSuiteForPaths(args)
+		}
+		if suite != "unit" && suite != "integration" {
+			fmt.Println("--suite must be unit or integration")
+			os.Exit(1)
+		}
+
+		command := []string{"php", "/var/www/html/w/tests/phpunit/phpunit.php"}
+		if suite == "unit" {
+			command = []string{"/var/www/html/w/vendor/bin/phpunit", "-c", "/var/www/html/w/phpunit.xml.dist"}
+		}
+		command = append(command, "--log-junit", mwddTestResultsFile("phpunit-"+suite+".xml"))
+		command = append(command, mwddTestPHPUnitFlags()...)
+		if len(args) == 0 {
+			command = append(command, "--testsuite", "core:"+suite)
+		}
+		for _, arg := range args {
+			command = append(command, mwdd.ShellQuote(arg))
+		}
+		mwddTestExec(command, suite == "integration")
+	},
+}
+
+var mwddMediawikiTestParserCmd = &cobra.Command{
+	Use:     "parser [flags] [-- phpunit arguments...]",
+	Short:   "Runs the parser tests of core and loaded extensions",
+	Example: "  test parser\n  test parser --filter Bold",
+	Run: func(cmd *cobra.Command, args []string) {
+		command := []string{
+			"php", "/var/www/html/w/tests/phpunit/phpunit.php",
+			"--testsuite", "parsertests",
+			"--log-junit", mwddTestResultsFile("parser.xml"),
+		}
+		command = append(command, mwddTestPHPUnitFlags()...)
+		for _, arg := range args {
+			command = append(command, mwdd.ShellQuote(arg))
+		}
+		mwddTestExec(command, true)
+	},
+}
+
+var mwddMediawikiTestQUnitCmd = &cobra.Command{
+	Use:     "qunit [-- npm script arguments...]",
+	Short:   "Runs the QUnit tests in headless Chromium, against the wiki of --db",
+	Example: "  test qunit\n  test qunit --db otherwiki",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwddTestBrowser("qunit", "qunit", args)
+	},
+}
+
+var mwddMediawikiTestSeleniumCmd = &cobra.Command{
+	Use:     "selenium [-- npm script arguments...]",
+	Short:   "Runs the Selenium tests in headless Chromium, against the wiki of --db",
+	Example: "  test selenium\n  test selenium -- --spec tests/selenium/specs/page.js",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwddTestBrowser("selenium-test", "selenium", args)
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiTestCmd)
+	mwddMediawikiTestCmd.AddCommand(mwddMediawikiTestPHPUnitCmd)
+	mwddMediawikiTestCmd.AddCommand(mwddMediawikiTestParserCmd)
+	mwddMediawikiTestCmd.AddCommand(mwddMediawikiTestQUnitCmd)
+	mwddMediawikiTestCmd.AddCommand(mwddMediawikiTestSeleniumCmd)
+	mwddMediawikiTestCmd.PersistentFlags().StringVarP(&TestDB, "db", "", "default", "Wiki database name to test against")
+	mwddMediawikiTestCmd.PersistentFlags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	mwddMediawikiTestCmd.RegisterFlagCompletionFunc("db", mwddWikiNameFlagCompletion)
+	mwddMediawikiTestPHPUnitCmd.Flags().StringVarP(&TestSuite, "suite", "", "", "unit or integration, detected from the paths by default")
+	mwddMediawikiTestPHPUnitCmd.Flags().StringVarP(&TestGroup, "group", "", "", "Only run tests in this group")
+	mwddMediawikiTestPHPUnitCmd.Flags().StringVarP(&TestFilter, "filter", "", "", "Only run tests matching this pattern")
+	mwddMediawikiTestParserCmd.Flags().StringVarP(&TestFilter, "filter", "", "", "Only run tests matching this pattern")
+}
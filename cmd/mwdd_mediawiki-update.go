@@ -0,0 +1,195 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+/*UpdateWiki used by the update command*/
+var UpdateWiki string
+
+/*UpdateAllWikis used by the update command*/
+var UpdateAllWikis bool
+
+/*UpdateQuick used by the update command*/
+var UpdateQuick bool
+
+type mwddUpdateResult struct {
+	wiki     string
+	dbType   string
+	output   string
+	err      error
+	duration time.Duration
+}
+
+// mwddUpdateCanRunInParallel sqlite databases are files that update.php can lock, so only server backends run in parallel
+func mwddUpdateCanRunInParallel(dbType string) bool {
+	return dbType == "mysql" || dbType == "postgres"
+}
+
+func mwddUpdateWiki(wiki string, dbType string) mwddUpdateResult {
+	command := []string{"php", "/var/www/html/w/maintenance/update.php", "--wiki", wiki}
+	if UpdateQuick {
+		command = append(command, "--quick")
+	}
+	start := time.Now()
+	output, err := mwdd.DefaultForUser().ExecWithOutput("mediawiki", command, User)
+	return mwddUpdateResult{
+		wiki:     wiki,
+		dbType:   dbType,
+		output:   output,
+		err:      err,
+		duration: time.Since(start),
+	}
+}
+
+func mwddUpdateWikis(wikis []string) []mwddUpdateResult {
+	records := mwdd.DefaultForUser().WikiRecords()
+	results := make([]mwddUpdateResult, len(wikis))
+
+	var wg sync.WaitGroup
+	sequential := []int{}
+	for i, wiki := range wikis {
+		dbType := records[wiki].Type
+		if dbType == "" {
+			dbType = "unknown"
+		}
+		results[i] = mwddUpdateResult{wiki: wiki, dbType: dbType}
+		if !mwddUpdateCanRunInParallel(dbType) {
+			sequential = append(sequential, i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = mwddUpdateWiki(results[i].wiki, results[i].dbType)
+		}(i)
+	}
+	for _, i := range sequential {
+		results[i] = mwddUpdateWiki(results[i].wiki, results[i].dbType)
+	}
+	wg.Wait()
+	return results
+}
+
+// mwddUpdateComposer offers to composer update core and the loaded extensions that have a composer.json
+func mwddUpdateComposer() {
+	composerErr := mwddComposerCheck()
+	if composerErr == nil {
+		return
+	}
+	fmt.Println("Composer check failed:", composerErr)
+	prompt := promptui.Prompt{
+		IsConfirm: true,
+		Label:     "Composer dependencies are not up to date, do you want to composer update core and extensions?",
+	}
+	if _, err := prompt.Run(); err != nil {
+		fmt.Println("Continuing without updating composer dependencies")
+		return
+	}
+	mwddComposer("", "update")
+	for _, extension := range mwddMaintExtensions("") {
+		directory := "extensions/" + extension
+		if _, err := os.Stat(mwddMediaWikiCode().Path(directory + "/composer.json")); err == nil {
+			fmt.Println("*** composer update in " + directory)
+			mwddComposer(directory, "update")
+		}
+	}
+}
+
+var mwddMediawikiUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Checks composer dependencies and runs update.php for one wiki, or all wikis",
+	Long: `Checks composer dependencies and runs update.php for one wiki, or all wikis.
+
+With --all-wikis, wikis using mysql or postgres are updated in parallel, and sqlite wikis one at a time.`,
+	Example: `  update
+  update --wiki otherwiki
+  update --all-wikis --quick`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if UpdateAllWikis && UpdateWiki != "" {
+			fmt.Println("Use either --wiki or --all-wikis, not both")
+			os.Exit(1)
+		}
+
+		mwddUpdateComposer()
+
+		wikis := []string{UpdateWiki}
+		if UpdateAllWikis {
+			wikis = mwdd.DefaultForUser().UsedDBNames()
+			sort.Strings(wikis)
+		} else if UpdateWiki == "" {
+			wikis = []string{"default"}
+		}
+		if len(wikis) == 0 {
+			fmt.Println("No wikis are installed")
+			os.Exit(1)
+		}
+
+		fmt.Println("Running update.php for " + strings.Join(wikis, ", "))
+		results := mwddUpdateWikis(wikis)
+
+		failed := false
+		for _, result := range results {
+			if result.err != nil || Verbosity >= 2 {
+				fmt.Println("*** update.php output for " + result.wiki)
+				fmt.Println(result.output)
+			}
+			if result.err != nil {
+				fmt.Println(result.err)
+				failed = true
+			}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WIKI\tDB\tRESULT\tDURATION")
+		for _, result := range results {
+			status := "ok"
+			if result.err != nil {
+				status = "failed"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.wiki, result.dbType, status, result.duration.Round(time.Second))
+		}
+		w.Flush()
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiUpdateCmd)
+	mwddMediawikiUpdateCmd.Flags().StringVarP(&UpdateWiki, "wiki", "w", "", "Wiki database name to update, defaults to default")
+	mwddMediawikiUpdateCmd.Flags().BoolVarP(&UpdateAllWikis, "all-wikis", "", false, "Update every installed wiki")
+	mwddMediawikiUpdateCmd.Flags().BoolVarP(&UpdateQuick, "quick", "", false, "Pass --quick to update.php, skipping the countdown")
+	mwddMediawikiUpdateCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	mwddMediawikiUpdateCmd.RegisterFlagCompletionFunc("wiki", mwddWikiNameFlagCompletion)
+}
@@ -0,0 +1,214 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*UserPassword used by the user create command*/
+var UserPassword string
+
+/*UserGroups used by the user create command*/
+var UserGroups string
+
+/*UserBulk used by the user create command*/
+var UserBulk string
+
+/*UserForce used by the user create command*/
+var UserForce bool
+
+/*UserListGroup used by the user list command*/
+var UserListGroup string
+
+// Lists users and changes groups, see static/mwdd/mediawiki/MwddUsers.php
+const mwddUsersScript = "/mwdd/MwddUsers.php"
+
+func mwddUserScript(wiki string, script string, scriptArgs ...string) int {
+	command := []string{"php", script, "--wiki", mwdd.ShellQuote(wiki)}
+	for _, arg := range scriptArgs {
+		command = append(command, mwdd.ShellQuote(arg))
+	}
+	return mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              command,
+		User:                 User,
+	})
+}
+
+func mwddUserCreate(wiki string, user mwdd.WikiUser) int {
+	scriptArgs := []string{}
+	if UserForce {
+		scriptArgs = append(scriptArgs, "--force")
+	}
+	if len(user.Groups) > 0 {
+		scriptArgs = append(scriptArgs, "--custom-groups", strings.Join(user.Groups, ","))
+	}
+	if user.Password == "" {
+		user.Password = mwddAdminPassword
+	}
+	scriptArgs = append(scriptArgs, user.Name, user.Password)
+	return mwddUserScript(wiki, "/var/www/html/w/maintenance/createAndPromote.php", scriptArgs...)
+}
+
+func mwddUserCreateBulk(wiki string, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	users, err := mwdd.ParseWikiUsersCSV(file)
+	file.Close()
+	if err != nil {
+		fmt.Println("Invalid CSV in " + path + ": " + err.Error())
+		os.Exit(1)
+	}
+
+	failed := []string{}
+	for _, user := range users {
+		if mwddUserCreate(wiki, user) != 0 {
+			failed = append(failed, user.Name)
+		}
+	}
+	fmt.Printf("Created %d of %d users\n", len(users)-len(failed), len(users))
+	if len(failed) > 0 {
+		fmt.Println("Failed for: " + strings.Join(failed, ", "))
+		os.Exit(1)
+	}
+}
+
+var mwddMediawikiUserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage the users of a wiki",
+	RunE:  nil,
+}
+
+var mwddMediawikiUserCreateCmd = &cobra.Command{
+	Use:   "create <wiki> <name>",
+	Short: "Creates a user, or many users from a CSV file",
+	Long: `Creates a user, or many users from a CSV file.
+
+The CSV file has rows of name,password,groups where groups are separated by semicolons or spaces.
+The password and groups are optional, and a header row starting with name is skipped.
+Users are created with the password of the admin user unless another is given.`,
+	Example: `  create default Alice
+  create default Bob --password secretpassword --groups sysop,bureaucrat
+  create default --bulk users.csv --force`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: mwddWikiNameCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if UserBulk != "" {
+			if len(args) != 1 {
+				fmt.Println("Don't give a user name with --bulk")
+				os.Exit(1)
+			}
+			mwddUserCreateBulk(args[0], UserBulk)
+			return
+		}
+		if len(args) != 2 {
+			fmt.Println("A user name is needed, or a CSV file with --bulk")
+			os.Exit(1)
+		}
+		os.Exit(mwddUserCreate(args[0], mwdd.WikiUser{
+			Name:     args[1],
+			Password: UserPassword,
+			Groups:   mwdd.ParseGroups(UserGroups),
+		}))
+	},
+}
+
+var mwddMediawikiUserListCmd = &cobra.Command{
+	Use:               "list <wiki>",
+	Short:             "Lists the users of a wiki and their groups",
+	Example:           "  list default\n  list default --group sysop",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: mwddWikiNameCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		command := []string{"php", mwddUsersScript, "--wiki", args[0]}
+		if UserListGroup != "" {
+			command = append(command, "--group", UserListGroup)
+		}
+		output, err := mwdd.DefaultForUser().ExecWithOutput("mediawiki", command, User)
+		if err != nil {
+			fmt.Println(output)
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tGROUPS")
+		fmt.Fprint(w, output)
+		w.Flush()
+	},
+}
+
+var mwddMediawikiUserPasswordCmd = &cobra.Command{
+	Use:               "password <wiki> <name> <password>",
+	Short:             "Changes the password of a user",
+	Example:           "  password default Alice newpassword",
+	Args:              cobra.ExactArgs(3),
+	ValidArgsFunction: mwddWikiNameCompletion,
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		os.Exit(mwddUserScript(args[0], "/var/www/html/w/maintenance/changePassword.php", "--user", args[1], "--password", args[2]))
+	},
+}
+
+var mwddMediawikiUserGroupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "Adds or removes a user from groups",
+	RunE:  nil,
+}
+
+func mwddUserGroupsCmd(action string, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:               action + " <wiki> <name> <groups...>",
+		Short:             short,
+		Example:           "  " + action + " default Alice sysop bureaucrat",
+		Args:              cobra.MinimumNArgs(3),
+		ValidArgsFunction: mwddWikiNameCompletion,
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			groups := mwdd.ParseGroups(strings.Join(args[2:], ","))
+			os.Exit(mwddUserScript(args[0], mwddUsersScript, "--"+action, strings.Join(groups, ","), args[1]))
+		},
+	}
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiUserCmd)
+	mwddMediawikiUserCmd.AddCommand(mwddMediawikiUserCreateCmd)
+	mwddMediawikiUserCmd.AddCommand(mwddMediawikiUserListCmd)
+	mwddMediawikiUserCmd.AddCommand(mwddMediawikiUserPasswordCmd)
+	mwddMediawikiUserCmd.AddCommand(mwddMediawikiUserGroupsCmd)
+	mwddMediawikiUserGroupsCmd.AddCommand(mwddUserGroupsCmd("add", "Adds a user to groups"))
+	mwddMediawikiUserGroupsCmd.AddCommand(mwddUserGroupsCmd("remove", "Removes a user from groups"))
+	mwddMediawikiUserCmd.PersistentFlags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run the scripts as, defaults to current OS user uid:gid")
+	mwddMediawikiUserCreateCmd.Flags().StringVarP(&UserPassword, "password", "p", "", "Password of the user, defaults to the password of the admin user")
+	mwddMediawikiUserCreateCmd.Flags().StringVarP(&UserGroups, "groups", "g", "", "Comma separated groups to add the user to, such as sysop,bureaucrat")
+	mwddMediawikiUserCreateCmd.Flags().StringVarP(&UserBulk, "bulk", "", "", "CSV file of users to create")
+	mwddMediawikiUserCreateCmd.Flags().BoolVarP(&UserForce, "force", "", false, "Update the password and groups of users that already exist")
+	mwddMediawikiUserListCmd.Flags().StringVarP(&UserListGroup, "group", "g", "", "Only list users in this group")
+}
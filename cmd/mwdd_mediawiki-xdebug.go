@@ -0,0 +1,123 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+var mwddMediawikiXdebugCmd = &cobra.Command{
+	Use:   "xdebug",
+	Short: "Control Xdebug in the MediaWiki container",
+	RunE:  nil,
+}
+
+func mwddMediawikiXdebugModeCmd(name string, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   name,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			options := exec.HandlerOptions{
+				Verbosity: Verbosity,
+			}
+			mode := mwdd.XdebugModes[name]
+			mwdd.DefaultForUser().SetXdebugMode(mode)
+			mwdd.DefaultForUser().Recreate([]string{"mediawiki"}, options)
+			mwdd.DefaultForUser().Exec("mediawiki", []string{"mkdir", "-p", mwdd.XdebugOutputDirectory}, options, "root")
+			mwdd.DefaultForUser().Exec("mediawiki", []string{"chmod", "777", mwdd.XdebugOutputDirectory}, options, "root")
+
+			fmt.Println("Xdebug mode is now: " + mode)
+			if name == "profile" || name == "trace" {
+				fmt.Println("Add XDEBUG_TRIGGER=1 as a GET or POST parameter, or as a cookie, to the requests you want to " + name + ".")
+				fmt.Println("You can then collect the output files with the `xdebug fetch` command.")
+			}
+		},
+	}
+}
+
+var mwddMediawikiXdebugOnCmd = mwddMediawikiXdebugModeCmd("on", "Turn on Xdebug step debugging (the default)")
+var mwddMediawikiXdebugOffCmd = mwddMediawikiXdebugModeCmd("off", "Turn off Xdebug entirely")
+var mwddMediawikiXdebugProfileCmd = mwddMediawikiXdebugModeCmd("profile", "Switch Xdebug to profiling mode")
+var mwddMediawikiXdebugTraceCmd = mwddMediawikiXdebugModeCmd("trace", "Switch Xdebug to function trace mode")
+
+var mwddMediawikiXdebugStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Outputs the current Xdebug configuration",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		mode := mwdd.DefaultForUser().XdebugMode()
+		config := mwdd.DefaultForUser().Env().Get("MEDIAWIKI_XDEBUG_CONFIG")
+		if config == "" {
+			config = "(none)"
+		}
+		fmt.Println("Mode:   " + mode)
+		fmt.Println("Config: " + config)
+		fmt.Println("")
+		fmt.Println("Changes only apply once the mediawiki container has been recreated, which the on, off, profile and trace commands do for you.")
+	},
+}
+
+var mwddMediawikiXdebugFetchCmd = &cobra.Command{
+	Use:     "fetch [directory]",
+	Short:   "Copies Xdebug profile and trace output from the MediaWiki container",
+	Example: "  fetch\n  fetch ~/xdebug-output",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		destination := "xdebug"
+		if len(args) == 1 {
+			destination = args[0]
+		}
+		destination, err := filepath.Abs(destination)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(destination, 0755); err != nil {
+			fmt.Println("Failed to create directory " + destination)
+			os.Exit(1)
+		}
+
+		mwdd.DefaultForUser().CopyFromContainer(
+			"mediawiki",
+			mwdd.XdebugOutputDirectory+"/.",
+			destination,
+			exec.HandlerOptions{
+				Verbosity: Verbosity,
+			},
+		)
+		fmt.Println("Xdebug output copied to " + destination)
+	},
+}
+
+func init() {
+	mwddMediawikiCmd.AddCommand(mwddMediawikiXdebugCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugOnCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugOffCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugProfileCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugTraceCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugStatusCmd)
+	mwddMediawikiXdebugCmd.AddCommand(mwddMediawikiXdebugFetchCmd)
+}
@@ -18,6 +18,7 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/user"
@@ -39,154 +40,166 @@ var mwddMediawikiCmd = &cobra.Command{
 	Aliases: []string{"mw"},
 	RunE:    nil,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		cmd.Parent().Parent().PersistentPreRun(cmd, args)
-		mwdd := mwdd.DefaultForUser()
-		mwdd.EnsureReady()
+		mwddCmd.PersistentPreRun(cmd, args)
+		mwddMediawikiSetup()
+	},
+}
 
-		usr, _ := user.Current()
-		usrDir := usr.HomeDir
+/*mwddMediawikiSetup makes sure that MediaWiki code is configured, offering to clone it when missing*/
+func mwddMediawikiSetup() {
+	mwdd := mwdd.DefaultForUser()
+	mwdd.EnsureReady()
 
-		if mwdd.Env().Missing("MEDIAWIKI_VOLUMES_CODE") {
+	usr, _ := user.Current()
+	usrDir := usr.HomeDir
 
-			// Try to autodetect if we are in a MediaWiki directory at all
-			suggestedMwDir, err := os.Getwd()
-			if err != nil {
-				panic(err)
-			}
-			for {
-				_, checkError := mediawiki.ForDirectory(suggestedMwDir)
-				if checkError == nil {
-					break
-				}
-				suggestedMwDir = filepath.Dir(suggestedMwDir)
-				if suggestedMwDir == "/" {
-					suggestedMwDir = "~/dev/git/gerrit/mediawiki/core"
-					break
-				}
-			}
+	if mwdd.Env().Missing("MEDIAWIKI_VOLUMES_CODE") {
 
-			// Prompt the user for a directory or confirmation
-			dirPrompt := promptui.Prompt{
-				Label:   "What directory would you like to store MediaWiki source code in?",
-				Default: suggestedMwDir,
-			}
-			value, err := dirPrompt.Run()
-
-			// Deal with people entering ~/ paths and them not be handled
-			if value == "~" {
-				// In case of "~", which won't be caught by the "else if"
-				value = usrDir
-			} else if strings.HasPrefix(value, "~/") {
-				// Use strings.HasPrefix so we don't match paths like
-				// "/something/~/something/"
-				value = filepath.Join(usrDir, value[2:])
+		// Try to autodetect if we are in a MediaWiki directory at all
+		suggestedMwDir, err := os.Getwd()
+		if err != nil {
+			panic(err)
+		}
+		for {
+			_, checkError := mediawiki.ForDirectory(suggestedMwDir)
+			if checkError == nil {
+				break
 			}
-
-			if err == nil {
-				mwdd.Env().Set("MEDIAWIKI_VOLUMES_CODE", value)
-			} else {
-				fmt.Println("Can't continue without a MediaWiki code directory")
-				os.Exit(1)
+			suggestedMwDir = filepath.Dir(suggestedMwDir)
+			if suggestedMwDir == "/" {
+				suggestedMwDir = "~/dev/git/gerrit/mediawiki/core"
+				break
 			}
+		}
 
+		// Prompt the user for a directory or confirmation
+		dirPrompt := promptui.Prompt{
+			Stdout:  os.Stderr,
+			Label:   "What directory would you like to store MediaWiki source code in?",
+			Default: suggestedMwDir,
+		}
+		value, err := dirPrompt.Run()
+
+		// Deal with people entering ~/ paths and them not be handled
+		if value == "~" {
+			// In case of "~", which won't be caught by the "else if"
+			value = usrDir
+		} else if strings.HasPrefix(value, "~/") {
+			// Use strings.HasPrefix so we don't match paths like
+			// "/something/~/something/"
+			value = filepath.Join(usrDir, value[2:])
 		}
 
-		// Default the mediawiki container to a .composer directory in the running users home dir
-		if !mwdd.Env().Has("MEDIAWIKI_VOLUMES_DOT_COMPOSER") {
-			usrComposerDirectory := usrDir + "/.composer"
-			if _, err := os.Stat(usrComposerDirectory); os.IsNotExist(err) {
-				err := os.Mkdir(usrComposerDirectory, 0755)
-				if err != nil {
-					fmt.Println("Failed to create directory needed for a composer cache")
-					os.Exit(1)
-				}
-			}
-			mwdd.Env().Set("MEDIAWIKI_VOLUMES_DOT_COMPOSER", usrDir+"/.composer")
+		if err == nil {
+			mwdd.Env().Set("MEDIAWIKI_VOLUMES_CODE", value)
+		} else {
+			fmt.Fprintln(os.Stderr, "Can't continue without a MediaWiki code directory")
+			os.Exit(1)
 		}
 
-		setupOpts := mediawiki.CloneSetupOpts{}
-		mediawiki, _ := mediawiki.ForDirectory(mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE"))
+	}
 
-		// TODO ask a question about what remotes you want to end up using? https vs ssh!
-		// TODO ask if they want to get any more skins and extensions?
-		// TODO async cloning of repos for speed!
-		if !mediawiki.MediaWikiIsPresent() {
-			cloneMwPrompt := promptui.Prompt{
-				Label:     "MediaWiki code not detected in " + mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE") + ". Do you want to clone it now?",
-				IsConfirm: true,
+	// Default the mediawiki container to a .composer directory in the running users home dir
+	if !mwdd.Env().Has("MEDIAWIKI_VOLUMES_DOT_COMPOSER") {
+		usrComposerDirectory := usrDir + "/.composer"
+		if _, err := os.Stat(usrComposerDirectory); os.IsNotExist(err) {
+			err := os.Mkdir(usrComposerDirectory, 0755)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to create directory needed for a composer cache")
+				os.Exit(1)
 			}
-			_, err := cloneMwPrompt.Run()
-			setupOpts.GetMediaWiki = err == nil
 		}
-		if !mediawiki.VectorIsPresent() {
-			cloneMwPrompt := promptui.Prompt{
-				Label:     "Vector skin is not detected in " + mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE") + ". Do you want to clone it from Gerrit?",
-				IsConfirm: true,
-			}
-			_, err := cloneMwPrompt.Run()
-			setupOpts.GetVector = err == nil
+		mwdd.Env().Set("MEDIAWIKI_VOLUMES_DOT_COMPOSER", usrDir+"/.composer")
+	}
+
+	setupOpts := mediawiki.CloneSetupOpts{}
+	mediawiki, _ := mediawiki.ForDirectory(mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE"))
+
+	// TODO ask a question about what remotes you want to end up using? https vs ssh!
+	// TODO ask if they want to get any more skins and extensions?
+	// TODO async cloning of repos for speed!
+	if !mediawiki.MediaWikiIsPresent() {
+		cloneMwPrompt := promptui.Prompt{
+			Stdout:    os.Stderr,
+			Label:     "MediaWiki code not detected in " + mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE") + ". Do you want to clone it now?",
+			IsConfirm: true,
 		}
-		if setupOpts.GetMediaWiki || setupOpts.GetVector {
-			cloneFromGithubPrompt := promptui.Prompt{
-				Label:     "Do you want to clone from Github for extra speed? (your git remotes will be switched to Gerrit after download)",
-				IsConfirm: true,
-			}
-			_, err := cloneFromGithubPrompt.Run()
-			setupOpts.UseGithub = err == nil
+		_, err := cloneMwPrompt.Run()
+		setupOpts.GetMediaWiki = err == nil
+	}
+	if !mediawiki.VectorIsPresent() {
+		cloneMwPrompt := promptui.Prompt{
+			Stdout:    os.Stderr,
+			Label:     "Vector skin is not detected in " + mwdd.Env().Get("MEDIAWIKI_VOLUMES_CODE") + ". Do you want to clone it from Gerrit?",
+			IsConfirm: true,
+		}
+		_, err := cloneMwPrompt.Run()
+		setupOpts.GetVector = err == nil
+	}
+	if setupOpts.GetMediaWiki || setupOpts.GetVector {
+		cloneFromGithubPrompt := promptui.Prompt{
+			Stdout:    os.Stderr,
+			Label:     "Do you want to clone from Github for extra speed? (your git remotes will be switched to Gerrit after download)",
+			IsConfirm: true,
+		}
+		_, err := cloneFromGithubPrompt.Run()
+		setupOpts.UseGithub = err == nil
 
-			cloneShallowPrompt := promptui.Prompt{
-				Label:     "Do you want to use shallow clones for extra speed? (You can fetch all history later using `git fetch --unshallow`)",
-				IsConfirm: true,
-			}
-			_, err = cloneShallowPrompt.Run()
-			setupOpts.UseShallow = err == nil
+		cloneShallowPrompt := promptui.Prompt{
+			Stdout:    os.Stderr,
+			Label:     "Do you want to use shallow clones for extra speed? (You can fetch all history later using `git fetch --unshallow`)",
+			IsConfirm: true,
+		}
+		_, err = cloneShallowPrompt.Run()
+		setupOpts.UseShallow = err == nil
 
-			finalRemoteTypePrompt := promptui.Prompt{
-				Label:   "How do you want to interact with Gerrit for the cloned repositores? (http or ssh)",
-				Default: "ssh",
+		finalRemoteTypePrompt := promptui.Prompt{
+			Stdout:  os.Stderr,
+			Label:   "How do you want to interact with Gerrit for the cloned repositores? (http or ssh)",
+			Default: "ssh",
+		}
+		remoteType, err := finalRemoteTypePrompt.Run()
+		if err != nil || (remoteType != "ssh" && remoteType != "http") {
+			fmt.Fprintln(os.Stderr, "Invalid Gerrit interaction type chosen.")
+			os.Exit(1)
+		}
+		setupOpts.GerritInteractionType = remoteType
+		if remoteType == "ssh" {
+			gerritUsernamePrompt := promptui.Prompt{
+				Stdout: os.Stderr,
+				Label:  "What is your Gerrit username?",
 			}
-			remoteType, err := finalRemoteTypePrompt.Run()
-			if err != nil || (remoteType != "ssh" && remoteType != "http") {
-				fmt.Println("Invalid Gerrit interaction type chosen.")
+			gerritUsername, err := gerritUsernamePrompt.Run()
+			if err != nil || len(gerritUsername) < 1 {
+				fmt.Fprintln(os.Stderr, "Gerrit username required for ssh interaction type.")
 				os.Exit(1)
 			}
-			setupOpts.GerritInteractionType = remoteType
-			if remoteType == "ssh" {
-				gerritUsernamePrompt := promptui.Prompt{
-					Label: "What is your Gerrit username?",
-				}
-				gerritUsername, err := gerritUsernamePrompt.Run()
-				if err != nil || len(gerritUsername) < 1 {
-					fmt.Println("Gerrit username required for ssh interaction type.")
-					os.Exit(1)
-				}
-				setupOpts.GerritUsername = gerritUsername
-			}
-			setupOpts.UseShallow = err == nil
+			setupOpts.GerritUsername = gerritUsername
 		}
+		setupOpts.UseShallow = err == nil
+	}
 
-		if setupOpts.GetMediaWiki || setupOpts.GetVector {
-			// Clone various things in multiple stages
-			Spinner := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-			Spinner.Prefix = "Performing step"
-			Spinner.FinalMSG = Spinner.Prefix + "(done)\n"
-			setupOpts.Options = exec.HandlerOptions{
-				Spinner: Spinner,
-			}
+	if setupOpts.GetMediaWiki || setupOpts.GetVector {
+		// Clone various things in multiple stages
+		Spinner := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+		Spinner.Prefix = "Performing step"
+		Spinner.FinalMSG = Spinner.Prefix + "(done)\n"
+		setupOpts.Options = exec.HandlerOptions{
+			Spinner: Spinner,
+		}
 
-			mediawiki.CloneSetup(setupOpts)
+		mediawiki.CloneSetup(setupOpts)
 
-			// Check that the needed things seem to have happened
-			if setupOpts.GetMediaWiki && !mediawiki.MediaWikiIsPresent() {
-				fmt.Println("Something went wrong cloning MediaWiki")
-				os.Exit(1)
-			}
-			if setupOpts.GetVector && !mediawiki.VectorIsPresent() {
-				fmt.Println("Something went wrong cloning Vector")
-				os.Exit(1)
-			}
+		// Check that the needed things seem to have happened
+		if setupOpts.GetMediaWiki && !mediawiki.MediaWikiIsPresent() {
+			fmt.Fprintln(os.Stderr, "Something went wrong cloning MediaWiki")
+			os.Exit(1)
 		}
-	},
+		if setupOpts.GetVector && !mediawiki.VectorIsPresent() {
+			fmt.Fprintln(os.Stderr, "Something went wrong cloning Vector")
+			os.Exit(1)
+		}
+	}
 }
 
 /*DbType used by the install command*/
@@ -206,179 +219,208 @@ var mwddMediawikiInstallCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// TODO check that the required DB services is running? OR start it up?
-
-		mediawiki, _ := mediawiki.ForDirectory(mwdd.DefaultForUser().Env().Get("MEDIAWIKI_VOLUMES_CODE"))
-		if !mediawiki.LocalSettingsIsPresent() {
-			prompt := promptui.Prompt{
-				IsConfirm: true,
-				Label:     "No LocalSettings.php detected. Do you want to create the default mwdd file?",
-			}
-			_, err := prompt.Run()
-			if err == nil {
-				lsPath := mediawiki.Path("LocalSettings.php")
-
-				f, err := os.Create(lsPath)
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
-				settingsStringToWrite := "<?php\n//require_once \"$IP/includes/PlatformSettings.php\";\nrequire_once '/mwdd/MwddSettings.php';\n"
-				if mediawiki.VectorIsPresent() {
-					settingsStringToWrite += "\nwfLoadSkin('Vector');\n"
-				}
-				_, err = f.WriteString(settingsStringToWrite)
-				if err != nil {
-					fmt.Println(err)
-					f.Close()
-					return
-				}
-				err = f.Close()
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
-			} else {
-				fmt.Println("Can't install without the expected LocalSettings.php file")
-				return
-			}
+		if err := mwddMediawikiInstall(DbType, DbName); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
+	},
+}
+
+/*mwddMediawikiInstall installs a wiki using install.php, creating the mwdd LocalSettings.php if needed*/
+func mwddMediawikiInstall(dbType string, dbName string) error {
+	// TODO check that the required DB services is running? OR start it up?
 
-		if !mediawiki.LocalSettingsContains("/mwdd/MwddSettings.php") {
-			fmt.Println("LocalSettings.php file exists, but doesn't look right (missing mwcli mwdd shim)")
-			return
+	mediawiki, _ := mediawiki.ForDirectory(mwdd.DefaultForUser().Env().Get("MEDIAWIKI_VOLUMES_CODE"))
+	if !mediawiki.LocalSettingsIsPresent() {
+		prompt := promptui.Prompt{
+			IsConfirm: true,
+			Label:     "No LocalSettings.php detected. Do you want to create the default mwdd file?",
 		}
+		_, err := prompt.Run()
+		if err == nil {
+			lsPath := mediawiki.Path("LocalSettings.php")
 
-		// TODO make sure of composer caches
-		composerErr := mwdd.DefaultForUser().ExecNoOutput("mediawiki", []string{
-			"php", "/var/www/html/w/maintenance/checkComposerLockUpToDate.php",
-		},
-			exec.HandlerOptions{}, User)
-		if composerErr != nil {
-			fmt.Println("Composer check failed:", composerErr)
-			prompt := promptui.Prompt{
-				IsConfirm: true,
-				Label:     "Composer dependencies are not up to date, do you want to composer install?",
+			f, err := os.Create(lsPath)
+			if err != nil {
+				return err
 			}
-			_, err := prompt.Run()
-			if err == nil {
-				mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
-					DockerComposeService: "mediawiki",
-					Command:              []string{"composer", "install", "--ignore-platform-reqs", "--no-interaction"},
-					User:                 User,
-				})
-			} else {
-				fmt.Println("Can't install without up to date composer dependencies")
-				os.Exit(1)
+			settingsStringToWrite := "<?php\n//require_once \"$IP/includes/PlatformSettings.php\";\nrequire_once '/mwdd/MwddSettings.php';\n"
+			if mediawiki.VectorIsPresent() {
+				settingsStringToWrite += "\nwfLoadSkin('Vector');\n"
+			}
+			_, err = f.WriteString(settingsStringToWrite)
+			if err != nil {
+				f.Close()
+				return err
+			}
+			err = f.Close()
+			if err != nil {
+				return err
 			}
+		} else {
+			return errors.New("Can't install without the expected LocalSettings.php file")
 		}
+	}
 
-		// Fix some permissions
-		mwdd.DefaultForUser().Exec("mediawiki", []string{"chown", "-R", "nobody", "/var/www/html/w/data"}, exec.HandlerOptions{}, "root")
-		mwdd.DefaultForUser().Exec("mediawiki", []string{"chown", "-R", "nobody", "/var/log/mediawiki"}, exec.HandlerOptions{}, "root")
-
-		// Record the wiki domain that we are trying to create
-		var domain string = DbName + ".mediawiki.mwdd.localhost"
-		mwdd.DefaultForUser().RecordHostUsageBySite(domain)
-
-		// Copy current local settings "somewhere safe", incase someone needs to restore it
-		currentTime := time.Now()
-		currentTimeString := currentTime.Format("20060102150405")
-		mwdd.DefaultForUser().Exec("mediawiki", []string{
-			"cp",
-			"/var/www/html/w/LocalSettings.php",
-			"/var/www/html/w/LocalSettings.php.mwdd.bak." + currentTimeString,
-		}, exec.HandlerOptions{}, User)
+	if !mediawiki.LocalSettingsContains("/mwdd/MwddSettings.php") {
+		return errors.New("LocalSettings.php file exists, but doesn't look right (missing mwcli mwdd shim)")
+	}
 
-		// Move custom LocalSetting.php so the install doesn't overwrite it
-		mwdd.DefaultForUser().Exec("mediawiki", []string{
-			"mv",
-			"/var/www/html/w/LocalSettings.php",
-			"/var/www/html/w/LocalSettings.php.mwdd.tmp",
-		}, exec.HandlerOptions{}, "root")
-
-		var serverLink string = "http://" + domain + ":" + mwdd.DefaultForUser().Env().Get("PORT")
-		const adminUser string = "admin"
-		const adminPass string = "mwddpassword"
-
-		// Do a DB type dependant install, writing the output LocalSettings.php to /tmp
-		if DbType == "sqlite" {
-			mwdd.DefaultForUser().Exec("mediawiki", []string{
-				"php",
-				"/var/www/html/w/maintenance/install.php",
-				"--confpath", "/tmp",
-				"--server", serverLink,
-				"--dbtype", DbType,
-				"--dbname", DbName,
-				"--lang", "en",
-				"--pass", adminPass,
-				"docker-" + DbName,
-				adminUser,
-			}, exec.HandlerOptions{}, "nobody")
+	// TODO make sure of composer caches
+	if composerErr := mwddComposerCheck(); composerErr != nil {
+		fmt.Println("Composer check failed:", composerErr)
+		prompt := promptui.Prompt{
+			IsConfirm: true,
+			Label:     "Composer dependencies are not up to date, do you want to composer install?",
 		}
-		if DbType == "mysql" {
-			mwdd.DefaultForUser().Exec("mediawiki", []string{
-				"/wait-for-it.sh",
-				"mysql:3306",
-			}, exec.HandlerOptions{}, "nobody")
+		if _, err := prompt.Run(); err != nil {
+			return errors.New("Can't install without up to date composer dependencies")
 		}
-		if DbType == "postgres" {
-			mwdd.DefaultForUser().Exec("mediawiki", []string{
-				"/wait-for-it.sh",
-				"postgres:5432",
-			}, exec.HandlerOptions{}, "nobody")
-		}
-		if DbType == "mysql" || DbType == "postgres" {
-			mwdd.DefaultForUser().Exec("mediawiki", []string{
-				"php",
-				"/var/www/html/w/maintenance/install.php",
-				"--confpath", "/tmp",
-				"--server", serverLink,
-				"--dbtype", DbType,
-				"--dbuser", "root",
-				"--dbpass", "toor",
-				"--dbname", DbName,
-				"--dbserver", DbType,
-				"--lang", "en",
-				"--pass", adminPass,
-				"docker-" + DbName,
-				adminUser,
-			}, exec.HandlerOptions{}, "nobody")
+		if mwddComposer("", "install") != 0 {
+			return errors.New("Composer install failed")
 		}
+	}
 
-		// Move the custom one back
+	// Fix some permissions
+	mwdd.DefaultForUser().Exec("mediawiki", []string{"chown", "-R", "nobody", "/var/www/html/w/data"}, exec.HandlerOptions{}, "root")
+	mwdd.DefaultForUser().Exec("mediawiki", []string{"chown", "-R", "nobody", "/var/log/mediawiki"}, exec.HandlerOptions{}, "root")
+
+	// Record the wiki domain that we are trying to create
+	var domain string = dbName + ".mediawiki.mwdd.localhost"
+	mwdd.DefaultForUser().RecordHostUsageBySite(domain)
+
+	// Copy current local settings "somewhere safe", incase someone needs to restore it
+	currentTime := time.Now()
+	currentTimeString := currentTime.Format("20060102150405")
+	mwdd.DefaultForUser().Exec("mediawiki", []string{
+		"cp",
+		"/var/www/html/w/LocalSettings.php",
+		"/var/www/html/w/LocalSettings.php.mwdd.bak." + currentTimeString,
+	}, exec.HandlerOptions{}, User)
+
+	// Move custom LocalSetting.php so the install doesn't overwrite it
+	mwdd.DefaultForUser().Exec("mediawiki", []string{
+		"mv",
+		"/var/www/html/w/LocalSettings.php",
+		"/var/www/html/w/LocalSettings.php.mwdd.tmp",
+	}, exec.HandlerOptions{}, "root")
+
+	var serverLink string = mwdd.DefaultForUser().HostURL(domain)
+
+	// Do a DB type dependant install, writing the output LocalSettings.php to /tmp
+	var installErr error
+	if dbType == "sqlite" {
+		installErr = mwddMediawikiInstallExec([]string{
+			"php",
+			"/var/www/html/w/maintenance/install.php",
+			"--confpath", "/tmp",
+			"--server", serverLink,
+			"--dbtype", dbType,
+			"--dbname", dbName,
+			"--lang", "en",
+			"--pass", mwddAdminPassword,
+			"docker-" + dbName,
+			mwddAdminUser,
+		}, "nobody")
+	}
+	if dbType == "mysql" {
 		mwdd.DefaultForUser().Exec("mediawiki", []string{
-			"mv",
-			"/var/www/html/w/LocalSettings.php.mwdd.tmp",
-			"/var/www/html/w/LocalSettings.php",
-		}, exec.HandlerOptions{}, "root")
-
-		// Run update.php once too
+			"/wait-for-it.sh",
+			"mysql:3306",
+		}, exec.HandlerOptions{}, "nobody")
+	}
+	if dbType == "postgres" {
 		mwdd.DefaultForUser().Exec("mediawiki", []string{
-			"php",
-			"/var/www/html/w/maintenance/update.php",
-			"--wiki", DbName,
-			"--quick",
+			"/wait-for-it.sh",
+			"postgres:5432",
 		}, exec.HandlerOptions{}, "nobody")
+	}
+	if dbType == "mysql" || dbType == "postgres" {
+		installErr = mwddMediawikiInstallExec([]string{
+			"php",
+			"/var/www/html/w/maintenance/install.php",
+			"--confpath", "/tmp",
+			"--server", serverLink,
+			"--dbtype", dbType,
+			"--dbuser", mwdd.DefaultForUser().DBRootUser(),
+			"--dbpass", mwdd.DefaultForUser().DBRootPassword(),
+			"--dbname", dbName,
+			"--dbserver", dbType,
+			"--lang", "en",
+			"--pass", mwddAdminPassword,
+			"docker-" + dbName,
+			mwddAdminUser,
+		}, "nobody")
+	}
 
-		fmt.Println("")
-		fmt.Println("***************************************")
-		fmt.Println("Installation successful 🎉")
-		fmt.Println("User: " + adminUser)
-		fmt.Println("Pass: " + adminPass)
-		fmt.Println("Link: " + serverLink)
-		fmt.Println("")
-		fmt.Println("If you want to access the wiki from your command line you may need to add it to your hosts file.")
-		fmt.Println("You can do this with the `hosts add` command that is part of this development environment.")
-		fmt.Println("***************************************")
-
-		// TODO remove once https://phabricator.wikimedia.org/T287654 is solved
-		if DbType == "sqlite" {
-			fmt.Println("WARNING: The sqlite development environment currently suffers an issue, https://phabricator.wikimedia.org/T287654")
-		}
-	},
+	// Move the custom one back
+	mwdd.DefaultForUser().Exec("mediawiki", []string{
+		"mv",
+		"/var/www/html/w/LocalSettings.php.mwdd.tmp",
+		"/var/www/html/w/LocalSettings.php",
+	}, exec.HandlerOptions{}, "root")
+	if installErr != nil {
+		return installErr
+	}
+
+	// Run update.php once too
+	if err := mwddMediawikiInstallExec([]string{
+		"php",
+		"/var/www/html/w/maintenance/update.php",
+		"--wiki", dbName,
+		"--quick",
+	}, "nobody"); err != nil {
+		return err
+	}
+
+	// Record the database of the wiki, so that MwddSettings.php doesn't need to look for it
+	dbServer := dbType
+	if dbType == "sqlite" {
+		dbServer = ""
+	}
+	if err := mwdd.DefaultForUser().RecordWiki(dbName, dbType, dbServer); err != nil {
+		fmt.Println("Failed to record the wiki, its database will be detected on each request:", err)
+	}
+
+	fmt.Println("")
+	fmt.Println("***************************************")
+	fmt.Println("Installation successful 🎉")
+	fmt.Println("User: " + mwddAdminUser)
+	fmt.Println("Pass: " + mwddAdminPassword)
+	fmt.Println("Link: " + serverLink)
+	fmt.Println("")
+	fmt.Println("If you want to access the wiki from your command line you may need to add it to your hosts file.")
+	fmt.Println("You can do this with the `hosts add` command that is part of this development environment.")
+	fmt.Println("***************************************")
+
+	// TODO remove once https://phabricator.wikimedia.org/T287654 is solved
+	if dbType == "sqlite" {
+		fmt.Println("WARNING: The sqlite development environment currently suffers an issue, https://phabricator.wikimedia.org/T287654")
+	}
+	return nil
+}
+
+// mwddMediawikiInstallExec runs a step of the install in the mediawiki container, with an error when it fails
+func mwddMediawikiInstallExec(command []string, user string) error {
+	quoted := []string{}
+	for _, arg := range command {
+		quoted = append(quoted, mwdd.ShellQuote(arg))
+	}
+	exitCode := mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              quoted,
+		User:                 user,
+	})
+	if exitCode != 0 {
+		return fmt.Errorf("%s failed with exit code %d", filepath.Base(command[1]), exitCode)
+	}
+	return nil
 }
 
+// The admin account created by the install command, also used by the browser tests
+const mwddAdminUser string = "admin"
+const mwddAdminPassword string = "mwddpassword"
+
 var mwddMediawikiComposerCmd = &cobra.Command{
 	Use:     "composer",
 	Short:   "Runs composer in a container in the context of MediaWiki",
@@ -416,6 +458,7 @@ var mwddMediawikiDestroyCmd = &cobra.Command{
 		}
 		mwdd.DefaultForUser().Rm([]string{"mediawiki", "mediawiki-web"}, options)
 		mwdd.DefaultForUser().RmVolumes([]string{"mediawiki-data", "mediawiki-images", "mediawiki-logs", "mediawiki-dot-composer"}, options)
+		mwdd.DefaultForUser().ForgetWikisOfType("sqlite")
 	},
 }
 
@@ -462,6 +505,24 @@ var mwddMediawikiExecCmd = &cobra.Command{
 	},
 }
 
+/*mwddComposerCheck checks that the composer dependencies of MediaWiki core are up to date*/
+func mwddComposerCheck() error {
+	return mwdd.DefaultForUser().ExecNoOutput("mediawiki", []string{
+		"php", "/var/www/html/w/maintenance/checkComposerLockUpToDate.php",
+	},
+		exec.HandlerOptions{}, User)
+}
+
+/*mwddComposer runs a composer command without interaction, in a directory relative to MediaWiki core*/
+func mwddComposer(directory string, command string) int {
+	return mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+		DockerComposeService: "mediawiki",
+		Command:              []string{"composer", command, "--ignore-platform-reqs", "--no-interaction"},
+		WorkingDir:           strings.TrimSuffix("/var/www/html/w/"+directory, "/"),
+		User:                 User,
+	})
+}
+
 var applyRelevantWorkingDirectory = func(dockerExecCommand mwdd.DockerExecCommand) mwdd.DockerExecCommand {
 	currentWorkingDirectory, _ := os.Getwd()
 	mountedMwDirectory := mwdd.DefaultForUser().Env().Get("MEDIAWIKI_VOLUMES_CODE")
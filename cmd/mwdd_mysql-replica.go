@@ -18,6 +18,10 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
 	"github.com/spf13/cobra"
@@ -94,6 +98,66 @@ var mwddMySQLReplicaExecCmd = &cobra.Command{
 	},
 }
 
+var mwddMySQLReplicaStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the state of replication from the MySQL container",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		status, err := mwdd.DefaultForUser().ReplicaStatus()
+		if err != nil {
+			fmt.Println("Could not get the replication status, is mysql-replica running?")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(status) == 0 {
+			fmt.Println("Replication is not configured, try the reset command")
+			os.Exit(1)
+		}
+		for _, field := range mwdd.ReplicaStatusFields {
+			value := status[field]
+			if value == "" {
+				value = "-"
+			}
+			fmt.Printf("%-22s %s\n", field+":", value)
+		}
+		if status["Slave_IO_Running"] != "Yes" || status["Slave_SQL_Running"] != "Yes" {
+			fmt.Println("")
+			fmt.Println("Replication is broken, the reset command will set it up again")
+		}
+	},
+}
+
+var mwddMySQLReplicaResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Sets up replication from the MySQL container again, starting from its current position",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		mwdd.DefaultForUser().ResetReplication(exec.HandlerOptions{
+			Verbosity: Verbosity,
+		})
+	},
+}
+
+var mwddMySQLReplicaLagCmd = &cobra.Command{
+	Use:     "lag [seconds]",
+	Short:   "Makes the replica lag behind the MySQL container, 0 to remove the lag",
+	Example: "  lag 10\n  lag 0",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		seconds, err := strconv.Atoi(args[0])
+		if err == nil {
+			err = mwdd.DefaultForUser().SetReplicaDelay(seconds)
+		}
+		if err != nil {
+			fmt.Println("Could not set the replica lag:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("Replica now lags by " + args[0] + " seconds")
+	},
+}
+
 func init() {
 	mwddCmd.AddCommand(mwddMySQLReplicaCmd)
 	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaCreateCmd)
@@ -101,5 +165,9 @@ func init() {
 	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaSuspendCmd)
 	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaResumeCmd)
 	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaExecCmd)
+	mwddMySQLReplicaCmd.AddCommand(mwddSQLCliCmd("mysql-replica"))
+	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaStatusCmd)
+	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaResetCmd)
+	mwddMySQLReplicaCmd.AddCommand(mwddMySQLReplicaLagCmd)
 	mwddMySQLReplicaExecCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
 }
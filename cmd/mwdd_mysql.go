@@ -53,6 +53,7 @@ var mwddMySQLDestroyCmd = &cobra.Command{
 		}
 		mwdd.DefaultForUser().Rm([]string{"mysql", "mysql-configure-replication"}, options)
 		mwdd.DefaultForUser().RmVolumes([]string{"mysql-data", "mysql-configure-replication-data"}, options)
+		mwdd.DefaultForUser().ForgetWikisOfType("mysql")
 	},
 }
 
@@ -101,5 +102,8 @@ func init() {
 	mwddMySQLCmd.AddCommand(mwddMySQLSuspendCmd)
 	mwddMySQLCmd.AddCommand(mwddMySQLResumeCmd)
 	mwddMySQLCmd.AddCommand(mwddMySQLExecCmd)
+	mwddMySQLCmd.AddCommand(mwddExposeCmds("mysql")...)
+	mwddMySQLCmd.AddCommand(mwddSQLCliCmd("mysql"))
+	mwddMySQLCmd.AddCommand(mwddDBVersionCmd("mysql", "  version\n  version mariadb:10.6\n  version mysql:8.0"))
 	mwddMySQLExecCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
 }
@@ -0,0 +1,155 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+// Versions are tags of the official node image, such as 14 or 16.13
+var mwddNodeVersionPattern = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+const mwddNodeDefaultVersion = "14"
+
+var mwddNodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Node.js service, for npm and other frontend tooling of MediaWiki",
+	RunE:  nil,
+}
+
+var mwddNodeCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a Node.js container",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		mwdd.DefaultForUser().UpDetached(
+			[]string{"node"},
+			exec.HandlerOptions{
+				Verbosity: Verbosity,
+			},
+		)
+	},
+}
+
+var mwddNodeDestroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Destroy the Node.js container and npm cache volume",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		options := exec.HandlerOptions{
+			Verbosity: Verbosity,
+		}
+		mwdd.DefaultForUser().Rm([]string{"node"}, options)
+		mwdd.DefaultForUser().RmVolumes([]string{"node-npm-cache"}, options)
+	},
+}
+
+var mwddNodeSuspendCmd = &cobra.Command{
+	Use:   "suspend",
+	Short: "Suspend the Node.js container",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		options := exec.HandlerOptions{
+			Verbosity: Verbosity,
+		}
+		mwdd.DefaultForUser().Stop([]string{"node"}, options)
+	},
+}
+
+var mwddNodeResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the Node.js container",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		options := exec.HandlerOptions{
+			Verbosity: Verbosity,
+		}
+		mwdd.DefaultForUser().Start([]string{"node"}, options)
+	},
+}
+
+var mwddNodeVersionCmd = &cobra.Command{
+	Use:     "version [version]",
+	Short:   "Shows or changes the version of Node.js that is used",
+	Example: "  version\n  version 16",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if len(args) == 0 {
+			current := mwdd.DefaultForUser().Env().Get("NODE_VERSION")
+			if current == "" {
+				current = mwddNodeDefaultVersion
+			}
+			fmt.Println(current)
+			return
+		}
+		if !mwddNodeVersionPattern.MatchString(args[0]) {
+			fmt.Println("Invalid Node.js version " + args[0] + ", expected something like 14 or 16.13")
+			os.Exit(1)
+		}
+		mwdd.DefaultForUser().Env().Set("NODE_VERSION", args[0])
+		mwddRecreateIfRunning("node")
+	},
+}
+
+var mwddNodeExecCmd = &cobra.Command{
+	Use:     "exec [flags] [command...]",
+	Example: "  exec bash\n  exec -- npx grunt test\n  exec --user root bash",
+	Short:   "Executes a command in the Node.js container, relative to the current directory when inside MediaWiki",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		mwdd.DefaultForUser().DockerExec(applyRelevantWorkingDirectory(mwdd.DockerExecCommand{
+			DockerComposeService: "node",
+			Command:              args,
+			User:                 User,
+		}))
+	},
+}
+
+var mwddNodeNpmCmd = &cobra.Command{
+	Use:     "npm [flags] [npm arguments...]",
+	Example: "  npm ci\n  npm test\n  npm -- run lint --fix",
+	Short:   "Runs npm in the Node.js container, relative to the current directory when inside MediaWiki",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		os.Exit(mwdd.DefaultForUser().DockerExec(applyRelevantWorkingDirectory(mwdd.DockerExecCommand{
+			DockerComposeService: "node",
+			Command:              append([]string{"npm"}, args...),
+			User:                 User,
+		})))
+	},
+}
+
+func init() {
+	mwddCmd.AddCommand(mwddNodeCmd)
+	mwddNodeCmd.AddCommand(mwddNodeCreateCmd)
+	mwddNodeCmd.AddCommand(mwddNodeDestroyCmd)
+	mwddNodeCmd.AddCommand(mwddNodeSuspendCmd)
+	mwddNodeCmd.AddCommand(mwddNodeResumeCmd)
+	mwddNodeCmd.AddCommand(mwddNodeVersionCmd)
+	mwddNodeCmd.AddCommand(mwddNodeExecCmd)
+	mwddNodeCmd.AddCommand(mwddNodeNpmCmd)
+	mwddNodeExecCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+	mwddNodeNpmCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+}
@@ -0,0 +1,117 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/ports"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+// The service that publishes PORT and HTTPS_PORT on the host
+const mwddPortService = "nginx-proxy"
+
+var mwddPortCmd = &cobra.Command{
+	Use:   "port",
+	Short: "Interact with the port that the development environment listens on",
+	RunE:  nil,
+}
+
+// mwddPortIsUsable checks that the port is either free, or already published by the environment
+func mwddPortIsUsable(port string) error {
+	if mwdd.DefaultForUser().ServicePublishesPort(mwddPortService, port) {
+		return nil
+	}
+	return ports.IsValidAndFree(port)
+}
+
+// mwddEnsurePortsUsable offers free replacements, as one set, for any of the ports in the .env keys taken by something else
+func mwddEnsurePortsUsable(keys []string) {
+	env := mwdd.DefaultForUser().Env()
+	unusable := []string{}
+	ranges := []ports.Range{}
+	usable := []string{}
+	for _, key := range keys {
+		if mwddPortIsUsable(env.Get(key)) == nil {
+			usable = append(usable, env.Get(key))
+			continue
+		}
+		unusable = append(unusable, key)
+		ranges = append(ranges, ports.RangeFrom(env.Get(key)))
+	}
+	if len(unusable) == 0 {
+		return
+	}
+
+	free, err := ports.FreeSet(ports.AllInterfaces, ranges, usable)
+	if err != nil {
+		fmt.Println("Ports in use by something else, and no free ports were found: " + err.Error())
+		os.Exit(1)
+	}
+	for i, key := range unusable {
+		fmt.Println(key + " " + env.Get(key) + " is no longer available, " + free[i] + " is free")
+	}
+	prompt := promptui.Prompt{
+		Label:     "Do you want to use the free ports instead?",
+		IsConfirm: true,
+	}
+	if _, err := prompt.Run(); err != nil {
+		fmt.Println("Can't continue while the ports are in use, free them or use the port set command")
+		os.Exit(1)
+	}
+	for i, key := range unusable {
+		env.Set(key, free[i])
+	}
+}
+
+var mwddPortSetCmd = &cobra.Command{
+	Use:   "set [port]",
+	Short: "Change the port that the development environment listens on",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		port := args[0]
+		if err := mwddPortIsUsable(port); err != nil {
+			fmt.Println("Can't use port " + port + ": " + err.Error())
+			os.Exit(1)
+		}
+		mwdd.DefaultForUser().Env().Set("PORT", port)
+
+		toRecreate := []string{}
+		for _, service := range []string{mwddPortService, "mediawiki"} {
+			if mwdd.DefaultForUser().ServiceIsRunning(service) {
+				toRecreate = append(toRecreate, service)
+			}
+		}
+		if len(toRecreate) > 0 {
+			mwdd.DefaultForUser().Recreate(toRecreate, exec.HandlerOptions{
+				Verbosity: Verbosity,
+			})
+		}
+		fmt.Println("The development environment now uses port " + port)
+	},
+}
+
+func init() {
+	mwddCmd.AddCommand(mwddPortCmd)
+	mwddPortCmd.AddCommand(mwddPortSetCmd)
+}
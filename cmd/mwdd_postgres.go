@@ -53,6 +53,7 @@ var mwddPostgresDestroyCmd = &cobra.Command{
 		}
 		mwdd.DefaultForUser().Rm([]string{"postgres"}, options)
 		mwdd.DefaultForUser().RmVolumes([]string{"postgres-data"}, options)
+		mwdd.DefaultForUser().ForgetWikisOfType("postgres")
 	},
 }
 
@@ -101,5 +102,8 @@ func init() {
 	mwddPostgresCmd.AddCommand(mwddPostgresSuspendCmd)
 	mwddPostgresCmd.AddCommand(mwddPostgresResumeCmd)
 	mwddPostgresCmd.AddCommand(mwddPostgresExecCmd)
+	mwddPostgresCmd.AddCommand(mwddExposeCmds("postgres")...)
+	mwddPostgresCmd.AddCommand(mwddSQLCliCmd("postgres"))
+	mwddPostgresCmd.AddCommand(mwddDBVersionCmd("postgres", "  version\n  version 14"))
 	mwddPostgresExecCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
 }
@@ -0,0 +1,189 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/hosts"
+	"github.com/spf13/cobra"
+)
+
+/*QuickstartDbType used by the quickstart command*/
+var QuickstartDbType string
+
+// Loaded for all wikis by the quickstart command, when they are in the extensions directory
+var mwddQuickstartExtensions = []string{"Cite", "CategoryTree", "ParserFunctions", "WikiEditor"}
+
+// The services to create for each database type, the first is the database server and others only run once
+var mwddQuickstartDBServices = map[string][]string{
+	"mysql":    {"mysql", "mysql-configure-replication"},
+	"postgres": {"postgres"},
+	"sqlite":   {},
+}
+
+const mwddQuickstartWiki = "default"
+
+// mwddQuickstartStep runs a step of the quickstart, unless it is already done
+func mwddQuickstartStep(name string, done bool, run func()) {
+	if done {
+		fmt.Println("✔ " + name + " (already done)")
+		return
+	}
+	fmt.Println("➜ " + name)
+	run()
+}
+
+func mwddQuickstartServicesRunning(services []string) bool {
+	for _, service := range services {
+		if !mwdd.DefaultForUser().ServiceIsRunning(service) {
+			return false
+		}
+	}
+	return true
+}
+
+// mwddQuickstartServices creates the database and MediaWiki services, unless they are running
+func mwddQuickstartServices(dbType string) {
+	m := mwdd.DefaultForUser()
+	dbServices := mwddQuickstartDBServices[dbType]
+	options := exec.HandlerOptions{
+		Verbosity: Verbosity,
+	}
+
+	dbRunning := len(dbServices) == 0 || m.ServiceIsRunning(dbServices[0])
+	mwddQuickstartStep("Create "+dbType+" services", dbRunning, func() {
+		m.UpDetached(dbServices, options)
+	})
+
+	mediawikiServices := []string{"mediawiki", "mediawiki-web"}
+	mwddQuickstartStep("Create MediaWiki services", mwddQuickstartServicesRunning(mediawikiServices), func() {
+		m.UpDetached(mediawikiServices, options)
+	})
+}
+
+func mwddQuickstartSettingsFile() string {
+	file, err := mwdd.DefaultForUser().SettingsFile(mwdd.SettingsForAllWikis, "Quickstart")
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+func mwddQuickstartWriteSettings() {
+	code := mwddMediaWikiCode()
+	content := "<?php\n// Written by `mw docker quickstart`, edit or remove it as you like\n"
+	missing := []string{}
+	for _, extension := range mwddQuickstartExtensions {
+		if _, err := os.Stat(code.Path("extensions/" + extension + "/extension.json")); err != nil {
+			missing = append(missing, extension)
+			continue
+		}
+		content += "wfLoadExtension( '" + extension + "' );\n"
+	}
+	if len(missing) > 0 {
+		fmt.Println("Not loading extensions that are not in " + code.Path("extensions") + ": " + strings.Join(missing, ", "))
+	}
+	if err := ioutil.WriteFile(mwddQuickstartSettingsFile(), []byte(content), 0644); err != nil {
+		fmt.Println("Failed to write the settings file:", err)
+		os.Exit(1)
+	}
+}
+
+func mwddQuickstartHosts() {
+	save := hosts.AddHosts(mwdd.DefaultForUser().Hosts())
+	if save.Success && save.BackupFile == "" {
+		fmt.Println("Hosts file already contains the development environment hosts")
+		return
+	}
+	mwddHostsPrintSave(save)
+}
+
+// mwddQuickstartCheckWiki waits for the wiki to respond, as services may still be starting
+func mwddQuickstartCheckWiki() {
+	var code int
+	var err error
+	for attempt := 0; attempt < 30; attempt++ {
+		code, err = mwdd.DefaultForUser().WikiResponseCode(mwddQuickstartWiki)
+		if err == nil && code == http.StatusOK {
+			fmt.Println("✔ Wiki responded with 200 OK")
+			return
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		fmt.Println("The wiki could not be reached:", err)
+	} else {
+		fmt.Printf("The wiki responded with %d, expected 200\n", code)
+	}
+	os.Exit(1)
+}
+
+var mwddQuickstartCmd = &cobra.Command{
+	Use:   "quickstart",
+	Short: "Sets up a working wiki in one go, skipping any steps that are already done",
+	Long: `Sets up a working wiki in one go, skipping any steps that are already done.
+
+This configures and clones MediaWiki code if needed, loads a default set of extensions for all wikis,
+creates the database and MediaWiki services, installs the default wiki, adds the hosts to your
+hosts file, and checks that the wiki responds.`,
+	Example: "  quickstart\n  quickstart --dbtype postgres",
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, ok := mwddQuickstartDBServices[QuickstartDbType]; !ok {
+			fmt.Println("You must specify a valid dbtype (mysql, postgres, sqlite)")
+			os.Exit(1)
+		}
+		m := mwdd.DefaultForUser()
+
+		mwddQuickstartStep("Configure MediaWiki code", false, mwddMediawikiSetup)
+
+		_, settingsErr := os.Stat(mwddQuickstartSettingsFile())
+		mwddQuickstartStep("Load default extensions", settingsErr == nil, mwddQuickstartWriteSettings)
+
+		mwddQuickstartServices(QuickstartDbType)
+
+		_, installed := m.WikiRecords()[mwddQuickstartWiki]
+		mwddQuickstartStep("Install the "+mwddQuickstartWiki+" wiki", installed, func() {
+			if err := mwddMediawikiInstall(QuickstartDbType, mwddQuickstartWiki); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		})
+
+		mwddQuickstartStep("Add hosts to your hosts file", false, mwddQuickstartHosts)
+
+		mwddQuickstartCheckWiki()
+		fmt.Println("")
+		fmt.Println("User: " + mwddAdminUser)
+		fmt.Println("Pass: " + mwddAdminPassword)
+		fmt.Println("Link: " + m.HostURL(mwddQuickstartWiki+".mediawiki."+mwdd.HostSuffix))
+	},
+}
+
+func init() {
+	mwddCmd.AddCommand(mwddQuickstartCmd)
+	mwddQuickstartCmd.Flags().StringVarP(&QuickstartDbType, "dbtype", "", "mysql", "Type of database to install (mysql, postgres, sqlite)")
+	mwddQuickstartCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
+}
@@ -113,6 +113,7 @@ func init() {
 	mwddRedisCmd.AddCommand(mwddRedisSuspendCmd)
 	mwddRedisCmd.AddCommand(mwddRedisResumeCmd)
 	mwddRedisCmd.AddCommand(mwddRedisExecCmd)
+	mwddRedisCmd.AddCommand(mwddExposeCmds("redis")...)
 	mwddRedisExecCmd.Flags().StringVarP(&User, "user", "u", mwdd.UserAndGroupForDockerExecution(), "User to run as, defaults to current OS user uid:gid")
 	mwddRedisCmd.AddCommand(mwddRedisCliCmd)
 }
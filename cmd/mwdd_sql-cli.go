@@ -0,0 +1,64 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// mwddStdioIsTerminal is the CLI being used interactively, rather than in a pipe or script
+func mwddStdioIsTerminal() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+/*mwddSQLCliCmd a cli command for a service with an SQL client, connecting to the database of a wiki*/
+func mwddSQLCliCmd(service string) *cobra.Command {
+	var execute string
+
+	cmd := &cobra.Command{
+		Use:               "cli [wiki]",
+		Short:             "SQL client connected to the database of a wiki",
+		Example:           "  cli\n  cli default\n  cli default -e \"SELECT * FROM user\"\n  cli default < dump.sql",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: mwddWikiNameCompletion,
+		Run: func(cmd *cobra.Command, args []string) {
+			mwdd.DefaultForUser().EnsureReady()
+			database := ""
+			if len(args) == 1 {
+				database = args[0]
+			}
+			command, err := mwdd.DefaultForUser().SQLClientCommand(service, database, execute)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			mwdd.DefaultForUser().DockerExec(mwdd.DockerExecCommand{
+				DockerComposeService: service,
+				Command:              command,
+				NoTTY:                !mwddStdioIsTerminal(),
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&execute, "execute", "e", "", "SQL to execute, rather than starting an interactive client")
+	return cmd
+}
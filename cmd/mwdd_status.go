@@ -0,0 +1,110 @@
+/*Package cmd is used for command line.
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd"
+	"github.com/spf13/cobra"
+)
+
+/*StatusWiki used by the status command*/
+var StatusWiki string
+
+func mwddStatusPrintMap(title string, values map[string]interface{}) {
+	fmt.Println(title + ":")
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-20s %v\n", key, values[key])
+	}
+}
+
+func mwddStatusEnvironment() {
+	status := mwdd.DefaultForUser().Status(Version)
+	fmt.Println("Running services: " + strings.Join(status.Services, ", "))
+	for _, service := range status.Services {
+		if link, ok := status.Links[service]; ok {
+			fmt.Println("  " + service + ": " + link)
+		}
+		if port, ok := status.ExposedPorts[service]; ok {
+			fmt.Println("  " + service + " exposed on port " + port)
+		}
+	}
+	fmt.Printf("https enabled: %v\n", status.HTTPS)
+	fmt.Println("Xdebug mode: " + status.XdebugMode)
+	if wikis := mwdd.DefaultForUser().UsedDBNames(); len(wikis) > 0 {
+		fmt.Println("Wikis: " + strings.Join(wikis, ", "))
+	}
+}
+
+func mwddStatusWiki(wiki string) {
+	status, err := mwdd.DefaultForUser().WikiStatus(wiki)
+	if err != nil {
+		fmt.Println("Could not get the status of " + wiki + ", is MediaWiki running?")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	db, _ := status["db"].(map[string]interface{})
+	fmt.Printf("Database: %v (%v)\n", db["name"], db["type"])
+	servers, _ := db["servers"].([]interface{})
+	for _, server := range servers {
+		server, _ := server.(map[string]interface{})
+		fmt.Printf("  %v load: %v lag: %v\n", server["host"], server["load"], server["lag"])
+	}
+	if services, ok := status["services"].(map[string]interface{}); ok {
+		mwddStatusPrintMap("Services", services)
+	}
+	if caches, ok := status["caches"].(map[string]interface{}); ok {
+		mwddStatusPrintMap("Caches", caches)
+	}
+	if versions, ok := status["versions"].(map[string]interface{}); ok {
+		mwddStatusPrintMap("Versions", versions)
+	}
+	if extensions, ok := status["extensions"].(map[string]interface{}); ok {
+		mwddStatusPrintMap("Extensions and skins", extensions)
+	}
+}
+
+var mwddStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Shows the status of the environment, or of a single wiki",
+	Example: "  status\n  status --wiki default",
+	Run: func(cmd *cobra.Command, args []string) {
+		mwdd.DefaultForUser().EnsureReady()
+		if StatusWiki == "" {
+			mwddStatusEnvironment()
+			return
+		}
+		mwddStatusWiki(StatusWiki)
+	},
+}
+
+func init() {
+	mwddCmd.AddCommand(mwddStatusCmd)
+	mwddStatusCmd.Flags().StringVarP(&StatusWiki, "wiki", "w", "", "Wiki database name, to show the status reported by Special:Mwdd")
+	mwddStatusCmd.RegisterFlagCompletionFunc("wiki", mwddWikiNameFlagCompletion)
+}
@@ -60,6 +60,13 @@ func ComposeCommand(context ComposeCommandContext, command string, arg ...string
 
 /*RunTTYCommand runs a command in an interactive shell*/
 func RunTTYCommand(options HandlerOptions, cmd *exec.Cmd) {
+	if err := RunTTYCommandWithError(options, cmd); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/*RunTTYCommandWithError runs a command in an interactive shell, returning the error rather than exiting*/
+func RunTTYCommandWithError(options HandlerOptions, cmd *exec.Cmd) error {
 	if options.Verbosity >= 2 {
 		fmt.Printf("\n%s\n", cmd.String())
 	}
@@ -67,11 +74,7 @@ func RunTTYCommand(options HandlerOptions, cmd *exec.Cmd) {
 	cmd.Stdout = os.Stdout
 	cmd.Stdin = os.Stdin
 	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-
-	if err != nil {
-		log.Fatal(err)
-	}
+	return cmd.Run()
 }
 
 /*RunCommand runs a command, handles verbose output and errors*/
@@ -0,0 +1,204 @@
+/*Package mediawiki is used to interact with MediaWiki
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mediawiki
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*ComposerUpToDate and the other statuses of ComposerState*/
+const (
+	ComposerUpToDate     = "up to date"
+	ComposerNotInstalled = "not installed"
+	ComposerOutdated     = "outdated"
+	ComposerChanged      = "composer.json changed"
+)
+
+/*ComposerState the state of the composer dependencies of a directory relative to MediaWiki*/
+type ComposerState struct {
+	Path    string
+	Status  string
+	Missing []string
+}
+
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+type composerPackage struct {
+	Name string `json:"name"`
+}
+
+// isComposerPlatformPackage requirements on php, extensions and composer itself are not installed into vendor
+func isComposerPlatformPackage(name string) bool {
+	return name == "php" || strings.HasPrefix(name, "ext-") || strings.HasPrefix(name, "lib-") || strings.HasPrefix(name, "composer-")
+}
+
+/*ComposerDirectories core, then every extension and skin with a composer.json, relative to MediaWiki, skipping those composer.local.json merges into core*/
+func (m MediaWiki) ComposerDirectories() []string {
+	includes := m.composerLocalIncludes()
+	directories := []string{""}
+	for _, kind := range []string{"extensions", "skins"} {
+		paths, _ := filepath.Glob(m.Path(kind + "/*/composer.json"))
+		sort.Strings(paths)
+		for _, path := range paths {
+			directory := kind + "/" + filepath.Base(filepath.Dir(path))
+			if !composerIncluded(includes, directory+"/composer.json") {
+				directories = append(directories, directory)
+			}
+		}
+	}
+	return directories
+}
+
+// composerLocalIncludes the merge plugin includes of composer.local.json, which may be globs
+func (m MediaWiki) composerLocalIncludes() []string {
+	local := struct {
+		Extra struct {
+			MergePlugin struct {
+				Include []string `json:"include"`
+			} `json:"merge-plugin"`
+		} `json:"extra"`
+	}{}
+	content, err := ioutil.ReadFile(m.Path("composer.local.json"))
+	if err != nil {
+		return nil
+	}
+	json.Unmarshal(content, &local)
+	return local.Extra.MergePlugin.Include
+}
+
+func composerIncluded(includes []string, path string) bool {
+	for _, include := range includes {
+		if matched, _ := filepath.Match(strings.TrimPrefix(include, "./"), path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// installedComposerPackages reads vendor/composer/installed.json, which is a list before composer 2 and an object after
+func installedComposerPackages(content []byte) (map[string]bool, error) {
+	var packages []composerPackage
+	if err := json.Unmarshal(content, &packages); err != nil {
+		var v2 struct {
+			Packages []composerPackage `json:"packages"`
+		}
+		if err := json.Unmarshal(content, &v2); err != nil {
+			return nil, err
+		}
+		packages = v2.Packages
+	}
+	installed := map[string]bool{}
+	for _, p := range packages {
+		installed[strings.ToLower(p.Name)] = true
+	}
+	return installed, nil
+}
+
+/*ComposerState checks that the packages required by a composer.json are installed, without checking their versions*/
+func (m MediaWiki) ComposerState(path string) ComposerState {
+	state := ComposerState{Path: path, Status: ComposerNotInstalled}
+	directory := m.Path(path)
+
+	installedFile := filepath.Join(directory, "vendor", "composer", "installed.json")
+	installedInfo, err := os.Stat(installedFile)
+	if err != nil {
+		return state
+	}
+	content, err := ioutil.ReadFile(filepath.Join(directory, "composer.json"))
+	if err != nil {
+		return state
+	}
+	var required composerJSON
+	if err := json.Unmarshal(content, &required); err != nil {
+		return state
+	}
+	installedContent, err := ioutil.ReadFile(installedFile)
+	if err != nil {
+		return state
+	}
+	installed, err := installedComposerPackages(installedContent)
+	if err != nil {
+		return state
+	}
+
+	for _, requirements := range []map[string]string{required.Require, required.RequireDev} {
+		for name := range requirements {
+			if !isComposerPlatformPackage(name) && !installed[strings.ToLower(name)] {
+				state.Missing = append(state.Missing, name)
+			}
+		}
+	}
+	sort.Strings(state.Missing)
+	if len(state.Missing) > 0 {
+		state.Status = ComposerOutdated
+		return state
+	}
+
+	if composerInfo, err := os.Stat(filepath.Join(directory, "composer.json")); err == nil && composerInfo.ModTime().After(installedInfo.ModTime()) {
+		state.Status = ComposerChanged
+		return state
+	}
+	state.Status = ComposerUpToDate
+	return state
+}
+
+/*AddComposerLocalInclude adds a composer.json, relative to MediaWiki, to the merge plugin includes of composer.local.json, returning if it was added*/
+func (m MediaWiki) AddComposerLocalInclude(path string) (bool, error) {
+	local := map[string]interface{}{}
+	content, err := ioutil.ReadFile(m.Path("composer.local.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &local); err != nil {
+			return false, err
+		}
+	}
+
+	extra, _ := local["extra"].(map[string]interface{})
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	mergePlugin, _ := extra["merge-plugin"].(map[string]interface{})
+	if mergePlugin == nil {
+		mergePlugin = map[string]interface{}{}
+	}
+	includes, _ := mergePlugin["include"].([]interface{})
+	for _, include := range includes {
+		if include == path {
+			return false, nil
+		}
+	}
+	mergePlugin["include"] = append(includes, path)
+	extra["merge-plugin"] = mergePlugin
+	local["extra"] = extra
+
+	content, err = json.MarshalIndent(local, "", "\t")
+	if err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(m.Path("composer.local.json"), append(content, '\n'), 0644)
+}
@@ -0,0 +1,95 @@
+/*Package mediawiki is used to interact with MediaWiki
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mediawiki
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Matches wfLoadExtension( 'Foo' ) and wfLoadExtensions( [ 'Foo', 'Bar' ] )
+var loadExtensionCall = regexp.MustCompile(`wfLoadExtensions?\s*\(([^;]*?)\)\s*;`)
+var quotedName = regexp.MustCompile(`['"]([^'"]+)['"]`)
+
+/*LoadedExtensions the extensions loaded by the given settings files, in the order that they are first loaded*/
+func LoadedExtensions(settingsFiles []string) []string {
+	seen := map[string]bool{}
+	extensions := []string{}
+	for _, file := range settingsFiles {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, call := range loadExtensionCall.FindAllStringSubmatch(string(content), -1) {
+			for _, name := range quotedName.FindAllStringSubmatch(call[1], -1) {
+				if !seen[name[1]] {
+					seen[name[1]] = true
+					extensions = append(extensions, name[1])
+				}
+			}
+		}
+	}
+	return extensions
+}
+
+// maintenanceScriptsIn lists runnable scripts, skipping class files such as Maintenance.php that start with a capital
+func maintenanceScriptsIn(directory string) []string {
+	paths, _ := filepath.Glob(filepath.Join(directory, "*.php"))
+	scripts := []string{}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".php")
+		if name != "" && !unicode.IsUpper(rune(name[0])) {
+			scripts = append(scripts, name)
+		}
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+/*MaintenanceScripts names of core scripts, and of extension scripts prefixed with the extension name, such as "Echo:script"*/
+func (m MediaWiki) MaintenanceScripts(extensions []string) []string {
+	scripts := maintenanceScriptsIn(m.Path("maintenance"))
+	for _, extension := range extensions {
+		for _, script := range maintenanceScriptsIn(m.Path(filepath.Join("extensions", extension, "maintenance"))) {
+			scripts = append(scripts, extension+":"+script)
+		}
+	}
+	return scripts
+}
+
+/*MaintenanceScriptPath the path of a script name from MaintenanceScripts, relative to the MediaWiki directory*/
+func (m MediaWiki) MaintenanceScriptPath(script string) (string, error) {
+	script = strings.TrimSuffix(script, ".php")
+	if strings.Contains(script, "..") {
+		return "", errors.New("invalid script name " + script)
+	}
+	relative := "maintenance/" + script + ".php"
+	if parts := strings.SplitN(script, ":", 2); len(parts) == 2 {
+		relative = "extensions/" + parts[0] + "/maintenance/" + parts[1] + ".php"
+	}
+	if _, err := os.Stat(m.Path(relative)); err != nil {
+		return "", errors.New("no maintenance script found at " + relative)
+	}
+	return relative, nil
+}
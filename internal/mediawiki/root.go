@@ -159,47 +159,73 @@ func (m MediaWiki) CloneSetup(options CloneSetupOpts) {
 		os.Exit(1)
 	}
 
-	shallowOptions := ""
-	if options.UseShallow {
-		shallowOptions = "--depth=1"
-	}
-
 	if options.GetMediaWiki {
-		exec.RunTTYCommand(options.Options, exec.Command(
-			"git",
-			"clone",
-			shallowOptions,
-			startRemoteCore,
-			m.Path("")))
-		if startRemoteCore != endRemoteCore {
-			exec.RunTTYCommand(options.Options, exec.Command(
-				"git",
-				"-C", m.Path(""),
-				"remote",
-				"set-url",
-				"origin",
-				endRemoteCore))
+		if err := m.cloneRepository(startRemoteCore, endRemoteCore, "", options); err != nil {
+			log.Fatal(err)
 		}
 	}
 	if options.GetVector {
-		exec.RunTTYCommand(options.Options, exec.Command(
-			"git",
-			"clone",
-			shallowOptions,
-			startRemoteVector,
-			m.Path("skins/Vector")))
-		if startRemoteCore != endRemoteCore {
-			exec.RunTTYCommand(options.Options, exec.Command(
-				"git",
-				"-C", m.Path("skins/Vector"),
-				"remote",
-				"set-url",
-				"origin",
-				endRemoteVector))
+		if err := m.cloneRepository(startRemoteVector, endRemoteVector, "skins/Vector", options); err != nil {
+			log.Fatal(err)
 		}
 	}
 }
 
+// cloneRepository clones into a path relative to MediaWiki, switching the origin remote once cloned if needed
+func (m MediaWiki) cloneRepository(startRemote string, endRemote string, path string, options CloneSetupOpts) error {
+	args := []string{"clone"}
+	if options.UseShallow {
+		args = append(args, "--depth=1")
+	}
+	if err := exec.RunTTYCommandWithError(options.Options, exec.Command("git", append(args, startRemote, m.Path(path))...)); err != nil {
+		return err
+	}
+	if startRemote != endRemote {
+		return exec.RunTTYCommandWithError(options.Options, exec.Command(
+			"git",
+			"-C", m.Path(path),
+			"remote",
+			"set-url",
+			"origin",
+			endRemote))
+	}
+	return nil
+}
+
+/*CloneProject clones a Gerrit project, such as mediawiki/extensions/Wikibase, into a path relative to MediaWiki*/
+func (m MediaWiki) CloneProject(project string, path string, submodules bool, options CloneSetupOpts) error {
+	exitIfNoGit()
+
+	startRemote := "https://gerrit.wikimedia.org/r/" + project
+	if options.UseGithub {
+		startRemote = "https://github.com/wikimedia/" + strings.Replace(project, "/", "-", -1) + ".git"
+	}
+	endRemote := "https://gerrit.wikimedia.org/r/" + project
+	if options.GerritInteractionType == "ssh" {
+		endRemote = "ssh://" + options.GerritUsername + "@gerrit.wikimedia.org:29418/" + project
+	}
+
+	if err := m.cloneRepository(startRemote, endRemote, path, options); err != nil {
+		return err
+	}
+	if submodules {
+		return exec.RunTTYCommandWithError(options.Options, exec.Command(
+			"git",
+			"-C", m.Path(path),
+			"submodule",
+			"update",
+			"--init",
+			"--recursive"))
+	}
+	return nil
+}
+
+/*ProjectIsPresent whether a path relative to MediaWiki looks like a cloned repository*/
+func (m MediaWiki) ProjectIsPresent(path string) bool {
+	_, err := os.Stat(m.Path(path + "/.git"))
+	return err == nil
+}
+
 /*GitCloneVector ...*/
 func (m MediaWiki) GitCloneVector(options exec.HandlerOptions) {
 	exitIfNoGit()
@@ -245,37 +271,37 @@ func (m MediaWiki) RenameLocalSettings() {
 	}
 }
 
-/*DeleteCache ...*/
+/*DeleteCache empties the cache directory of MediaWiki, keeping its .htaccess file*/
 func (m MediaWiki) DeleteCache() {
-	err := os.Rename("cache/.htaccess", ".htaccess.fromcache.tmp")
+	err := os.Rename(m.Path("cache/.htaccess"), m.Path(".htaccess.fromcache.tmp"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.RemoveAll("./cache/")
+	err = os.RemoveAll(m.Path("cache"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.Mkdir("cache", 0700)
+	err = os.Mkdir(m.Path("cache"), 0700)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.Rename(".htaccess.fromcache.tmp", "cache/.htaccess")
+	err = os.Rename(m.Path(".htaccess.fromcache.tmp"), m.Path("cache/.htaccess"))
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-/*DeleteVendor ...*/
+/*DeleteVendor empties the vendor directory of MediaWiki*/
 func (m MediaWiki) DeleteVendor() {
-	err := os.RemoveAll("./vendor")
+	err := os.RemoveAll(m.Path("vendor"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.Mkdir("vendor", 0700)
+	err = os.Mkdir(m.Path("vendor"), 0700)
 	if err != nil {
 		log.Fatal(err)
 	}
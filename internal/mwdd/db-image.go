@@ -0,0 +1,112 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+)
+
+/*DBImage how the image of a database, and the services and volumes using it, are configured*/
+type DBImage struct {
+	EnvKey  string
+	Default string
+	// Flavors that can be used as the image name, the first is used when only a version is given
+	Flavors  []string
+	Services []string
+	// Volumes holding database data, that should be kept in a snapshot before wiping
+	DataVolumes []string
+	// Volumes that are wiped alongside the data, but are not worth keeping
+	StateVolumes []string
+}
+
+/*DBImages database images that can be changed, keyed by the main docker-compose service*/
+var DBImages = map[string]DBImage{
+	"mysql": {
+		EnvKey:       "MYSQL_IMAGE",
+		Default:      "mariadb:10.5",
+		Flavors:      []string{"mariadb", "mysql"},
+		Services:     []string{"mysql", "mysql-configure-replication", "mysql-replica", "mysql-replica-configure-replication"},
+		DataVolumes:  []string{"mysql-data", "mysql-replica-data"},
+		StateVolumes: []string{"mysql-configure-replication-data"},
+	},
+	"postgres": {
+		EnvKey:      "POSTGRES_IMAGE",
+		Default:     "postgres:13.2",
+		Flavors:     []string{"postgres"},
+		Services:    []string{"postgres"},
+		DataVolumes: []string{"postgres-data"},
+	},
+}
+
+var imageVersion = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+/*Parse a requested flavor:version, or just a version, into an image name*/
+func (d DBImage) Parse(requested string) (string, error) {
+	flavor, version := d.Flavors[0], requested
+	if parts := strings.SplitN(requested, ":", 2); len(parts) == 2 {
+		flavor, version = parts[0], parts[1]
+	}
+	known := false
+	for _, f := range d.Flavors {
+		known = known || f == flavor
+	}
+	if !known {
+		return "", errors.New("unknown flavor " + flavor + ", expected one of " + strings.Join(d.Flavors, ", "))
+	}
+	if !imageVersion.MatchString(version) {
+		return "", errors.New("invalid version " + version)
+	}
+	return flavor + ":" + version, nil
+}
+
+/*DBImage the image currently configured for a database*/
+func (m MWDD) DBImage(service string) string {
+	d := DBImages[service]
+	if m.Env().Missing(d.EnvKey) {
+		return d.Default
+	}
+	return m.Env().Get(d.EnvKey)
+}
+
+/*SnapshotDirectory where snapshots of volumes are written*/
+func (m MWDD) SnapshotDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "snapshots"
+}
+
+/*SnapshotVolume writes a tar.gz of the docker-compose volume to the snapshot directory, returning its path*/
+func (m MWDD) SnapshotVolume(dcVolume string, options exec.HandlerOptions) (string, error) {
+	if err := os.MkdirAll(m.SnapshotDirectory(), 0755); err != nil {
+		return "", err
+	}
+	name := dcVolume + "-" + time.Now().Format("20060102150405") + ".tar.gz"
+	err := exec.RunCommand(options, exec.Command(
+		"docker", "run", "--rm",
+		"-v", m.DockerComposeProjectName()+"_"+dcVolume+":/volume:ro",
+		"-v", m.SnapshotDirectory()+":/snapshot",
+		"alpine:3",
+		// Root is needed to read all of the data, so hand the snapshot back to the current user
+		"sh", "-c", "tar -czf /snapshot/"+name+" -C /volume . && chown "+UserAndGroupForDockerExecution()+" /snapshot/"+name,
+	))
+	return m.SnapshotDirectory() + string(os.PathSeparator) + name, err
+}
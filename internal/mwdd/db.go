@@ -0,0 +1,134 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The user used before it was configurable, and the default for new environments
+const defaultDBRootUser = "root"
+
+// Passwords used before they were configurable, kept for environments that already have database volumes
+const legacyDBRootPassword = "toor"
+const legacyDBReplicationPassword = "repl"
+
+// Volumes that would have been created with the legacy passwords
+var dbVolumes = []string{"mysql-data", "mysql-replica-data", "postgres-data"}
+
+/*DBRootUser the user that wikis and tools connect to the databases as*/
+func (m MWDD) DBRootUser() string {
+	if m.Env().Missing("DB_ROOT_USER") {
+		return defaultDBRootUser
+	}
+	return m.Env().Get("DB_ROOT_USER")
+}
+
+/*DBRootPassword the password of DBRootUser*/
+func (m MWDD) DBRootPassword() string {
+	if m.Env().Missing("DB_ROOT_PASSWORD") {
+		return legacyDBRootPassword
+	}
+	return m.Env().Get("DB_ROOT_PASSWORD")
+}
+
+/*EnsureDBCredentials makes sure that DB_ROOT_USER, DB_ROOT_PASSWORD and DB_REPLICATION_PASSWORD are set, generating random passwords for new environments*/
+func (m MWDD) EnsureDBCredentials() error {
+	if m.Env().Missing("DB_ROOT_USER") {
+		m.Env().Set("DB_ROOT_USER", defaultDBRootUser)
+	}
+	if m.Env().Has("DB_ROOT_PASSWORD") && m.Env().Has("DB_REPLICATION_PASSWORD") {
+		return nil
+	}
+
+	// Random passwords would lock an existing environment out of its databases, so only use them when docker says there are none
+	volumesExist, err := m.dbVolumesExist()
+	if err != nil {
+		return fmt.Errorf("unable to check for existing database volumes: %v", err)
+	}
+	rootPassword, replicationPassword := legacyDBRootPassword, legacyDBReplicationPassword
+	if !volumesExist {
+		if rootPassword, err = randomPassword(); err != nil {
+			return err
+		}
+		if replicationPassword, err = randomPassword(); err != nil {
+			return err
+		}
+	}
+	if m.Env().Missing("DB_ROOT_PASSWORD") {
+		m.Env().Set("DB_ROOT_PASSWORD", rootPassword)
+	}
+	if m.Env().Missing("DB_REPLICATION_PASSWORD") {
+		m.Env().Set("DB_REPLICATION_PASSWORD", replicationPassword)
+	}
+	return nil
+}
+
+func (m MWDD) dbVolumesExist() (bool, error) {
+	for _, volume := range dbVolumes {
+		exists, err := m.VolumeExists(volume)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// Hex keeps the password safe to use in URLs, shells and YAML without escaping
+func randomPassword() (string, error) {
+	bytes := make([]byte, 12)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+/*SQLClientCommand the command to run in the service for an SQL client connected to the database, optionally executing sql*/
+func (m MWDD) SQLClientCommand(service string, database string, sql string) ([]string, error) {
+	var command []string
+	switch service {
+	case "mysql", "mysql-replica":
+		command = []string{"mysql", ShellQuote("-u" + m.DBRootUser()), ShellQuote("-p" + m.DBRootPassword())}
+		if database != "" {
+			command = append(command, ShellQuote(database))
+		}
+		if sql != "" {
+			command = append(command, "-e", ShellQuote(sql))
+		}
+	case "postgres":
+		command = []string{"env", ShellQuote("PGPASSWORD=" + m.DBRootPassword()), "psql", "-U", ShellQuote(m.DBRootUser())}
+		if database != "" {
+			command = append(command, "-d", ShellQuote(database))
+		}
+		if sql != "" {
+			command = append(command, "-c", ShellQuote(sql))
+		}
+	default:
+		return nil, errors.New(service + " does not have an SQL client")
+	}
+	return command, nil
+}
+
+/*ShellQuote quotes a value so that it is passed as a single argument by sh, as DockerExec runs commands with sh -c*/
+func ShellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'"'"'`, -1) + "'"
+}
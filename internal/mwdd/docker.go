@@ -31,6 +31,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/pkg/stdcopy"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -40,7 +41,12 @@ type DockerExecCommand struct {
 	Command              []string
 	WorkingDir           string
 	User                 string
-	HandlerOptions       exec.HandlerOptions
+	// NoTTY runs without a TTY, so that stdin and stdout can be piped
+	NoTTY bool
+	// Stdin and Stdout replace os.Stdin and os.Stdout when set, only used with NoTTY
+	Stdin          io.Reader
+	Stdout         io.Writer
+	HandlerOptions exec.HandlerOptions
 }
 
 /*UserAndGroupForDockerExecution gets a user and group id combination for the current user that can be used for execution*/
@@ -53,11 +59,65 @@ func UserAndGroupForDockerExecution() string {
 	return fmt.Sprint(os.Getuid(), ":", os.Getgid())
 }
 
-/*DockerExec runs a docker exec command using the docker SDK*/
-func (m MWDD) DockerExec(command DockerExecCommand) {
-	containerID := m.DockerComposeProjectName() + "_" + command.DockerComposeService + "_1"
+func dockerClient() (*client.Client, error) {
+	return client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+}
+
+func (m MWDD) containerID(service string) string {
+	return m.DockerComposeProjectName() + "_" + service + "_1"
+}
+
+/*ServiceIsRunning is the container for the docker-compose service currently running*/
+func (m MWDD) ServiceIsRunning(service string) bool {
+	cli, err := dockerClient()
+	if err != nil {
+		return false
+	}
+	container, err := cli.ContainerInspect(context.Background(), m.containerID(service))
+	if err != nil {
+		return false
+	}
+	return container.State != nil && container.State.Running
+}
+
+/*ServicePublishesPort is the container for the docker-compose service publishing the host port*/
+func (m MWDD) ServicePublishesPort(service string, port string) bool {
+	cli, err := dockerClient()
+	if err != nil {
+		return false
+	}
+	container, err := cli.ContainerInspect(context.Background(), m.containerID(service))
+	if err != nil || container.HostConfig == nil {
+		return false
+	}
+	for _, bindings := range container.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			if binding.HostPort == port {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*VolumeExists has the docker-compose volume been created, with an error when docker could not be asked*/
+func (m MWDD) VolumeExists(dcVolume string) (bool, error) {
+	cli, err := dockerClient()
+	if err != nil {
+		return false, err
+	}
+	_, err = cli.VolumeInspect(context.Background(), m.DockerComposeProjectName()+"_"+dcVolume)
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+/*DockerExec runs a docker exec command using the docker SDK, returning the exit code of the command, or -1 if it could not be run*/
+func (m MWDD) DockerExec(command DockerExecCommand) int {
+	containerID := m.containerID(command.DockerComposeService)
 
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	cli, err := dockerClient()
 	if err != nil {
 		fmt.Println("Unable to create docker client")
 		panic(err)
@@ -67,7 +127,7 @@ func (m MWDD) DockerExec(command DockerExecCommand) {
 		AttachStderr: true,
 		AttachStdout: true,
 		AttachStdin:  true,
-		Tty:          true,
+		Tty:          !command.NoTTY,
 		WorkingDir:   command.WorkingDir,
 		User:         command.User,
 		Cmd:          []string{"/bin/sh", "-c", strings.Join(command.Command, " ")},
@@ -76,23 +136,42 @@ func (m MWDD) DockerExec(command DockerExecCommand) {
 	ctx := context.Background()
 	response, err := cli.ContainerExecCreate(ctx, containerID, execConfig)
 	if err != nil {
-		return
+		return -1
 	}
 
 	execID := response.ID
 	if execID == "" {
 		fmt.Println("exec ID empty")
-		return
+		return -1
 	}
 
 	execStartCheck := types.ExecStartCheck{
-		Tty: true,
+		Tty: execConfig.Tty,
 	}
 
 	waiter, err := cli.ContainerExecAttach(ctx, execID, execStartCheck)
 	if err != nil {
 		fmt.Println(err)
-		return
+		return -1
+	}
+
+	if !execConfig.Tty {
+		defer waiter.Close()
+		stdin := command.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		stdout := command.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		go func() {
+			io.Copy(waiter.Conn, stdin)
+			waiter.CloseWrite()
+		}()
+		// Without a TTY stdout and stderr are multiplexed, and the stream ends with the command
+		stdcopy.StdCopy(stdout, os.Stderr, waiter.Reader)
+		return execExitCode(ctx, cli, execID)
 	}
 
 	if execConfig.Tty {
@@ -117,16 +196,20 @@ func (m MWDD) DockerExec(command DockerExecCommand) {
 		defer terminal.Restore(fd, oldState)
 	}
 
+	return execExitCode(ctx, cli, execID)
+}
+
+// execExitCode waits for an exec to stop running, and returns its exit code
+func execExitCode(ctx context.Context, cli *client.Client, execID string) int {
 	for {
 		resp, err := cli.ContainerExecInspect(ctx, execID)
-		time.Sleep(50 * time.Millisecond)
 		if err != nil {
-			break
+			return -1
 		}
-
 		if !resp.Running {
-			break
+			return resp.ExitCode
 		}
+		time.Sleep(50 * time.Millisecond)
 	}
 }
 
@@ -0,0 +1,171 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*FarmSharedDBKey the .env key of the wiki that mysql wikis of the farm share user tables with*/
+const FarmSharedDBKey = "FARM_SHARED_DB"
+
+// Loaded for all wikis from settings.d, so that they know about each other
+const farmSettingsFileName = "Farm.php"
+
+// The tables that must be shared together for wikis to share users
+var farmSharedTables = []string{"user", "user_properties", "user_autocreate_serial", "actor"}
+
+/*FarmSettingsFile the settings file that configures the farm*/
+func (m MWDD) FarmSettingsFile() string {
+	file, err := m.SettingsFile(SettingsForAllWikis, farmSettingsFileName)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+/*FarmEnabled whether the farm settings file exists*/
+func (m MWDD) FarmEnabled() bool {
+	_, err := os.Stat(m.FarmSettingsFile())
+	return err == nil
+}
+
+/*FarmSharedDB the wiki that mysql wikis share user tables with, if it is a recorded mysql wiki*/
+func (m MWDD) FarmSharedDB() string {
+	shared := m.Env().Get(FarmSharedDBKey)
+	if record, ok := m.WikiRecords()[shared]; ok && record.Type == "mysql" {
+		return shared
+	}
+	return ""
+}
+
+/*SetFarmSharedDB sets the wiki that mysql wikis share user tables with, or stops sharing for an empty wiki*/
+func (m MWDD) SetFarmSharedDB(wiki string) error {
+	if wiki == "" {
+		m.Env().Delete(FarmSharedDBKey)
+		return nil
+	}
+	record, ok := m.WikiRecords()[wiki]
+	if !ok {
+		return errors.New("no wiki called " + wiki + " has been installed")
+	}
+	if record.Type != "mysql" {
+		return errors.New("tables can only be shared between mysql wikis, " + wiki + " uses " + record.Type)
+	}
+	m.Env().Set(FarmSharedDBKey, wiki)
+	return nil
+}
+
+/*SyncFarm writes the farm settings file for all recorded wikis*/
+func (m MWDD) SyncFarm() error {
+	m.ensureSettingsDirectory()
+	return ioutil.WriteFile(m.FarmSettingsFile(), []byte(m.farmSettings()), 0644)
+}
+
+/*RemoveFarm removes the farm settings file*/
+func (m MWDD) RemoveFarm() error {
+	err := os.Remove(m.FarmSettingsFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// syncFarmIfEnabled keeps the farm settings in line with the recorded wikis
+func (m MWDD) syncFarmIfEnabled() {
+	if m.FarmEnabled() {
+		m.SyncFarm()
+	}
+}
+
+func phpString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func (m MWDD) farmSettings() string {
+	records := m.WikiRecords()
+	wikis := []string{}
+	for wiki := range records {
+		wikis = append(wikis, wiki)
+	}
+	sort.Strings(wikis)
+
+	var b strings.Builder
+	b.WriteString("<?php\n")
+	b.WriteString("// Generated by `mw docker mediawiki farm sync` for all installed wikis, changes will be overwritten\n\n")
+
+	b.WriteString("// Database name => database type\n")
+	b.WriteString("$mwddFarmWikis = [\n")
+	for _, wiki := range wikis {
+		fmt.Fprintf(&b, "\t%s => %s,\n", phpString(wiki), phpString(records[wiki].Type))
+	}
+	b.WriteString("];\n")
+	b.WriteString("$wgLocalDatabases = array_keys( $mwddFarmWikis );\n\n")
+
+	b.WriteString(`// The server of another wiki, worked out from the server of this one so that port and https changes apply
+$mwddFarmServer = static function ( string $db ) use ( $wgServer, $dockerDb ): string {
+	return str_replace( "//$dockerDb.mediawiki.", "//$db.mediawiki.", $wgServer );
+};
+
+$wgConf->wikis = $wgLocalDatabases;
+foreach ( $mwddFarmWikis as $mwddFarmWiki => $mwddFarmDbType ) {
+	$wgConf->settings['wgServer'][$mwddFarmWiki] = $mwddFarmServer( $mwddFarmWiki );
+	$wgConf->settings['wgCanonicalServer'][$mwddFarmWiki] = $mwddFarmServer( $mwddFarmWiki );
+	$wgConf->settings['wgScriptPath'][$mwddFarmWiki] = $wgScriptPath;
+	$wgConf->settings['wgDBname'][$mwddFarmWiki] = $mwddFarmWiki;
+	$wgConf->settings['wgDBtype'][$mwddFarmWiki] = $mwddFarmDbType;
+}
+
+// Interwiki links between the wikis, with lower case database names as prefixes
+$wgHooks['InterwikiLoadPrefix'][] = static function ( $prefix, &$iwData ) use ( $mwddFarmWikis, $mwddFarmServer ) {
+	foreach ( $mwddFarmWikis as $mwddFarmWiki => $mwddFarmDbType ) {
+		if ( strtolower( $mwddFarmWiki ) === $prefix ) {
+			$server = $mwddFarmServer( $mwddFarmWiki );
+			$iwData = [
+				'iw_prefix' => $prefix,
+				'iw_url' => "$server/w/index.php?title=$1",
+				'iw_api' => "$server/w/api.php",
+				'iw_wikiid' => $mwddFarmWiki,
+				'iw_local' => 1,
+				'iw_trans' => 0,
+			];
+			return false;
+		}
+	}
+	return true;
+};
+`)
+
+	if shared := m.FarmSharedDB(); shared != "" {
+		tables := []string{}
+		for _, table := range farmSharedTables {
+			tables = append(tables, phpString(table))
+		}
+		fmt.Fprintf(&b, "\n// Users are shared with the %s wiki, by mysql wikis on the same server\n", shared)
+		fmt.Fprintf(&b, "if ( $dockerDbType === 'mysql' && $dockerDb !== %s ) {\n", phpString(shared))
+		fmt.Fprintf(&b, "\t$wgSharedDB = %s;\n", phpString(shared))
+		fmt.Fprintf(&b, "\t$wgSharedTables = [ %s ];\n", strings.Join(tables, ", "))
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
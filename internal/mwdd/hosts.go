@@ -0,0 +1,132 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd/files"
+	"gopkg.in/yaml.v2"
+)
+
+/*HostSuffix all hosts of the development environment end with this*/
+const HostSuffix = "mwdd.localhost"
+
+// The default wiki is always expected to exist, even before anything is installed
+const defaultWikiHost = "default.mediawiki." + HostSuffix
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Hostname string `yaml:"hostname"`
+	// Either a list of KEY=value strings, or a map
+	Environment interface{} `yaml:"environment"`
+}
+
+func (s composeService) environmentValue(name string) string {
+	switch environment := s.Environment.(type) {
+	case []interface{}:
+		for _, item := range environment {
+			entry, ok := item.(string)
+			if !ok {
+				continue
+			}
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) == 2 && parts[0] == name {
+				return parts[1]
+			}
+		}
+	case map[interface{}]interface{}:
+		return environmentScalar(environment[name])
+	}
+	return ""
+}
+
+// environmentScalar YAML reads unquoted values such as 8080 or true as numbers and booleans, and empty values as nil
+func environmentScalar(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+/*Hosts all hosts that the development environment uses, read from the docker-compose files and recorded wikis*/
+func (m MWDD) Hosts() []string {
+	found := map[string]bool{
+		defaultWikiHost: true,
+	}
+
+	for _, file := range files.ListRawDcYamlFilesInContextOfProjectDirectory(m.Directory()) {
+		for _, host := range hostsInComposeFile(m.Directory() + string(os.PathSeparator) + file) {
+			if strings.HasPrefix(host, "*.") {
+				for _, used := range m.UsedHosts() {
+					if strings.HasSuffix(used, host[1:]) {
+						found[used] = true
+					}
+				}
+				continue
+			}
+			found[host] = true
+		}
+	}
+
+	hosts := []string{}
+	for host := range found {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func hostsInComposeFile(filePath string) []string {
+	hosts := []string{}
+
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return hosts
+	}
+	var parsed composeFile
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return hosts
+	}
+
+	for _, service := range parsed.Services {
+		candidates := strings.Split(service.environmentValue("VIRTUAL_HOST"), ",")
+		candidates = append(candidates, service.Hostname)
+		for _, candidate := range candidates {
+			candidate = strings.TrimSpace(candidate)
+			// Ignore hosts that are only resolvable within the docker network, or use variables
+			if !strings.HasSuffix(candidate, HostSuffix) || strings.Contains(candidate, "$") {
+				continue
+			}
+			hosts = append(hosts, candidate)
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,154 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"io/ioutil"
+	"os"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/certs"
+)
+
+const httpsOverride = "https"
+
+// nginx-proxy picks the certificate with the longest name that the virtual host ends with,
+// so a single mwdd.localhost certificate is used for every service.
+const httpsCertName = "mwdd.localhost"
+
+/*HTTPSHosts hosts that the generated certificate is valid for*/
+var HTTPSHosts = []string{
+	"mwdd.localhost",
+	"*.mwdd.localhost",
+	"*.mediawiki.mwdd.localhost",
+}
+
+// HTTPS_METHOD=noredirect keeps the plain http port working alongside https
+const httpsOverrideContent = `version: '3.7'
+
+# Generated by mwcli when https is enabled, remove with the https disable command
+
+services:
+  nginx-proxy:
+    ports:
+      - "${HTTPS_PORT}:443"
+    environment:
+      - HTTPS_METHOD=noredirect
+    volumes:
+      - ./https/certs:/etc/nginx/certs:ro
+
+  mediawiki:
+    environment:
+      - MWDD_HTTPS_PORT=${HTTPS_PORT}
+
+  mediawiki-web:
+    environment:
+      - HTTPS_METHOD=noredirect
+
+  adminer:
+    environment:
+      - HTTPS_METHOD=noredirect
+
+  phpmyadmin:
+    environment:
+      - HTTPS_METHOD=noredirect
+
+  graphite:
+    environment:
+      - HTTPS_METHOD=noredirect
+`
+
+func (m MWDD) httpsDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "https"
+}
+
+/*CACertificatePath the location of the local certificate authority that needs to be trusted*/
+func (m MWDD) CACertificatePath() string {
+	return m.httpsDirectory() + string(os.PathSeparator) + "ca.crt"
+}
+
+func (m MWDD) caKeyPath() string {
+	return m.httpsDirectory() + string(os.PathSeparator) + "ca.key"
+}
+
+func (m MWDD) certsDirectory() string {
+	return m.httpsDirectory() + string(os.PathSeparator) + "certs"
+}
+
+/*HTTPSEnabled is the https override currently in place*/
+func (m MWDD) HTTPSEnabled() bool {
+	return m.HasOverride(httpsOverride)
+}
+
+/*EnableHTTPS generates the certificate authority (if needed) and certificate, and writes the https override*/
+func (m MWDD) EnableHTTPS() error {
+	if err := os.MkdirAll(m.certsDirectory(), 0700); err != nil {
+		return err
+	}
+
+	ca, err := m.ensureCA()
+	if err != nil {
+		return err
+	}
+	cert, err := certs.GenerateSigned(ca, HTTPSHosts)
+	if err != nil {
+		return err
+	}
+	certPath := m.certsDirectory() + string(os.PathSeparator) + httpsCertName
+	if err := ioutil.WriteFile(certPath+".crt", cert.Certificate, 0644); err != nil {
+		return err
+	}
+	// Readable by the nginx user in the container
+	if err := ioutil.WriteFile(certPath+".key", cert.Key, 0644); err != nil {
+		return err
+	}
+
+	return m.WriteOverride(httpsOverride, httpsOverrideContent)
+}
+
+/*DisableHTTPS removes the https override, leaving the certificate authority in place*/
+func (m MWDD) DisableHTTPS() error {
+	return m.RemoveOverride(httpsOverride)
+}
+
+func (m MWDD) ensureCA() (certs.Pair, error) {
+	certificate, certErr := ioutil.ReadFile(m.CACertificatePath())
+	key, keyErr := ioutil.ReadFile(m.caKeyPath())
+	if certErr == nil && keyErr == nil {
+		return certs.Pair{Certificate: certificate, Key: key}, nil
+	}
+
+	ca, err := certs.GenerateCA("mwcli development environment CA")
+	if err != nil {
+		return ca, err
+	}
+	if err := ioutil.WriteFile(m.CACertificatePath(), ca.Certificate, 0644); err != nil {
+		return ca, err
+	}
+	if err := ioutil.WriteFile(m.caKeyPath(), ca.Key, 0600); err != nil {
+		return ca, err
+	}
+	return ca, nil
+}
+
+/*HostURL the base URL that the host can be reached at from the host machine*/
+func (m MWDD) HostURL(host string) string {
+	if m.HTTPSEnabled() {
+		return "https://" + host + ":" + m.Env().Get("HTTPS_PORT")
+	}
+	return "http://" + host + ":" + m.Env().Get("PORT")
+}
@@ -0,0 +1,133 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/subnets"
+	"github.com/docker/docker/api/types"
+)
+
+// The DNS proxy has always lived at .10 in the network, so keep that for new subnets too
+const dnsHostNumber = 10
+
+func (m MWDD) networkName() string {
+	return m.DockerComposeProjectName() + "_dps"
+}
+
+/*EnsureNetworkSettings makes sure that NETWORK_SUBNET and NETWORK_DNS_IP are set and valid, picking free values if needed*/
+func (m MWDD) EnsureNetworkSettings() error {
+	if m.Env().Missing("NETWORK_SUBNET") {
+		subnet, err := m.chooseSubnet()
+		if err != nil {
+			return err
+		}
+		m.Env().Set("NETWORK_SUBNET", subnet)
+	}
+	subnet := m.Env().Get("NETWORK_SUBNET")
+	if err := subnets.Validate(subnet); err != nil {
+		return errors.New("NETWORK_SUBNET " + subnet + ": " + err.Error())
+	}
+
+	if m.Env().Missing("NETWORK_DNS_IP") {
+		ipNet, _ := subnets.Parse(subnet)
+		m.Env().Set("NETWORK_DNS_IP", subnets.HostAt(ipNet, dnsHostNumber).String())
+	}
+	dnsIP := m.Env().Get("NETWORK_DNS_IP")
+	if err := subnets.ValidateHostIP(subnet, dnsIP); err != nil {
+		return errors.New("NETWORK_DNS_IP " + dnsIP + ": " + err.Error())
+	}
+
+	return nil
+}
+
+/*NetworkGateway the address of the host within the development environment network*/
+func (m MWDD) NetworkGateway() string {
+	ipNet, err := subnets.Parse(m.Env().Get("NETWORK_SUBNET"))
+	if err != nil {
+		return ""
+	}
+	return subnets.HostAt(ipNet, 1).String()
+}
+
+/*NetworkSubnetInUse the subnet of the already created docker network, or an empty string if it has not been created*/
+func (m MWDD) NetworkSubnetInUse() string {
+	cli, err := dockerClient()
+	if err != nil {
+		return ""
+	}
+	network, err := cli.NetworkInspect(context.Background(), m.networkName(), types.NetworkInspectOptions{})
+	if err != nil {
+		return ""
+	}
+	for _, config := range network.IPAM.Config {
+		return config.Subnet
+	}
+	return ""
+}
+
+func (m MWDD) chooseSubnet() (string, error) {
+	// Keep using the subnet of an existing network, so that it doesn't need to be recreated
+	if inUse := m.NetworkSubnetInUse(); inUse != "" {
+		return inUse, nil
+	}
+
+	used, err := m.usedSubnets()
+	if err != nil {
+		return "", err
+	}
+	return subnets.FirstFree(used)
+}
+
+/*usedSubnets lists subnets used by other docker networks and the host network interfaces (such as VPNs)*/
+func (m MWDD) usedSubnets() ([]*net.IPNet, error) {
+	used := []*net.IPNet{}
+
+	cli, err := dockerClient()
+	if err != nil {
+		return used, err
+	}
+	networks, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
+	if err != nil {
+		return used, errors.New("unable to list docker networks, is docker running? " + err.Error())
+	}
+	for _, network := range networks {
+		if network.Name == m.networkName() {
+			continue
+		}
+		for _, config := range network.IPAM.Config {
+			if _, ipNet, err := net.ParseCIDR(config.Subnet); err == nil {
+				used = append(used, ipNet)
+			}
+		}
+	}
+
+	addresses, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, address := range addresses {
+			if ipNet, ok := address.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				used = append(used, &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask})
+			}
+		}
+	}
+
+	return used, nil
+}
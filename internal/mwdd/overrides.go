@@ -0,0 +1,50 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// Override files are docker-compose files generated by the CLI rather than packaged with it.
+// They live next to the packaged files, so they are loaded with every docker-compose command.
+
+func (m MWDD) overrideFile(name string) string {
+	return m.Directory() + string(os.PathSeparator) + name + ".override.yml"
+}
+
+/*HasOverride is the named override file currently on disk*/
+func (m MWDD) HasOverride(name string) bool {
+	_, err := os.Stat(m.overrideFile(name))
+	return err == nil
+}
+
+/*WriteOverride writes the named override file, replacing any existing content*/
+func (m MWDD) WriteOverride(name string, content string) error {
+	return ioutil.WriteFile(m.overrideFile(name), []byte(content), 0644)
+}
+
+/*RemoveOverride removes the named override file if it exists*/
+func (m MWDD) RemoveOverride(name string) error {
+	err := os.Remove(m.overrideFile(name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*Preset a set of repositories, wikis and settings that are set up together, read from presets/<name>.json*/
+type Preset struct {
+	Description string   `json:"description"`
+	DBTypes     []string `json:"dbtypes"`
+	// Farm configures the wikis to know about each other, see SyncFarm
+	Farm         bool               `json:"farm"`
+	Repositories []PresetRepository `json:"repositories"`
+	Wikis        []PresetWiki       `json:"wikis"`
+}
+
+/*PresetRepository a Gerrit project to clone into a path relative to MediaWiki*/
+type PresetRepository struct {
+	Project    string `json:"project"`
+	Path       string `json:"path"`
+	Submodules bool   `json:"submodules"`
+	// ComposerMerge adds the composer.json of the repository to composer.local.json of MediaWiki
+	ComposerMerge bool `json:"composerMerge"`
+}
+
+/*PresetWiki a wiki to install with its own settings file, and maintenance scripts to run once it is installed*/
+type PresetWiki struct {
+	Name     string     `json:"name"`
+	Settings []string   `json:"settings"`
+	Scripts  [][]string `json:"scripts"`
+}
+
+var presetServerPlaceholder = regexp.MustCompile(`\{\{server:([A-Za-z0-9_]+)\}\}`)
+
+func (m MWDD) presetsDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "presets"
+}
+
+/*Presets the names of all presets*/
+func (m MWDD) Presets() []string {
+	names := []string{}
+	paths, _ := filepath.Glob(filepath.Join(m.presetsDirectory(), "*.json"))
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*Preset reads a preset by name*/
+func (m MWDD) Preset(name string) (Preset, error) {
+	var preset Preset
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return preset, errors.New("invalid preset name " + name)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(m.presetsDirectory(), name+".json"))
+	if os.IsNotExist(err) {
+		return preset, errors.New("no preset called " + name + ", presets are " + strings.Join(m.Presets(), ", "))
+	}
+	if err != nil {
+		return preset, err
+	}
+	if err := json.Unmarshal(content, &preset); err != nil {
+		return preset, errors.New("invalid preset " + name + ": " + err.Error())
+	}
+	return preset, nil
+}
+
+/*ExpandPresetPlaceholders replaces {{server:<wiki>}} with the URL of a wiki*/
+func (m MWDD) ExpandPresetPlaceholders(text string) string {
+	return presetServerPlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		wiki := presetServerPlaceholder.FindStringSubmatch(placeholder)[1]
+		return m.HostURL(wiki + ".mediawiki." + HostSuffix)
+	})
+}
+
+/*WritePresetSettings writes the settings of a preset for a wiki into its settings.d directory*/
+func (m MWDD) WritePresetSettings(presetName string, wiki PresetWiki) error {
+	file, err := m.SettingsFile(wiki.Name, "Preset-"+presetName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	content := "<?php\n// Written by `mw docker mediawiki preset " + presetName + "`, changes will be overwritten when it is applied again\n"
+	for _, line := range wiki.Settings {
+		content += m.ExpandPresetPlaceholders(line) + "\n"
+	}
+	return ioutil.WriteFile(file, []byte(content), 0644)
+}
+
+// presetScriptsRecord how many scripts of a preset have run for a wiki, for the install of the wiki it was created for
+type presetScriptsRecord struct {
+	Created string `json:"created"`
+	Done    int    `json:"done"`
+}
+
+func (m MWDD) presetScriptsRecordFile(presetName string, wiki string) string {
+	return filepath.Join(m.recordsDirectory(), "presets", presetName+"."+wiki+".json")
+}
+
+/*PresetScriptsDone how many scripts of a preset have run in order for the current install of a wiki, as scripts such as addSite.php can't run twice*/
+func (m MWDD) PresetScriptsDone(presetName string, wiki string) int {
+	content, err := ioutil.ReadFile(m.presetScriptsRecordFile(presetName, wiki))
+	if err != nil {
+		return 0
+	}
+	var record presetScriptsRecord
+	if json.Unmarshal(content, &record) != nil {
+		return 0
+	}
+	// A reinstalled wiki needs all of the scripts again
+	if wikiRecord, ok := m.WikiRecords()[wiki]; !ok || wikiRecord.Created != record.Created {
+		return 0
+	}
+	return record.Done
+}
+
+/*RecordPresetScriptsDone records how many scripts of a preset have run for the current install of a wiki*/
+func (m MWDD) RecordPresetScriptsDone(presetName string, wiki string, done int) error {
+	content, err := json.MarshalIndent(presetScriptsRecord{
+		Created: m.WikiRecords()[wiki].Created,
+		Done:    done,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := m.presetScriptsRecordFile(presetName, wiki)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
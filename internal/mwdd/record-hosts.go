@@ -19,6 +19,7 @@ package mwdd
 
 import (
 	"os"
+	"strings"
 
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/util/files"
 )
@@ -36,3 +37,14 @@ func (m MWDD) RecordHostUsageBySite(host string) {
 func (m MWDD) UsedHosts() []string {
 	return files.Lines(m.hostRecordFile())
 }
+
+/*UsedDBNames lists the database names of all wikis that have been used at some point*/
+func (m MWDD) UsedDBNames() []string {
+	names := []string{}
+	for _, host := range m.UsedHosts() {
+		if strings.HasSuffix(host, ".mediawiki."+HostSuffix) {
+			names = append(names, strings.TrimSuffix(host, ".mediawiki."+HostSuffix))
+		}
+	}
+	return names
+}
@@ -0,0 +1,99 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*WikiRecord metadata about an installed wiki, read by MwddSettings.php to avoid probing for the database*/
+type WikiRecord struct {
+	Type    string `json:"type"`
+	Server  string `json:"server"`
+	Created string `json:"created"`
+}
+
+// Mounted into the mediawiki container at /mwdd-records
+func (m MWDD) recordsDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "records"
+}
+
+func (m MWDD) wikiRecordsDirectory() string {
+	return m.recordsDirectory() + string(os.PathSeparator) + "wikis"
+}
+
+func (m MWDD) wikiRecordFile(dbName string) string {
+	return m.wikiRecordsDirectory() + string(os.PathSeparator) + dbName + ".json"
+}
+
+func (m MWDD) ensureRecordsDirectory() {
+	if err := os.MkdirAll(m.wikiRecordsDirectory(), 0755); err != nil {
+		panic(err)
+	}
+}
+
+/*RecordWiki records the database of an installed wiki*/
+func (m MWDD) RecordWiki(dbName string, dbType string, dbServer string) error {
+	content, err := json.MarshalIndent(WikiRecord{
+		Type:    dbType,
+		Server:  dbServer,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	m.ensureRecordsDirectory()
+	if err := ioutil.WriteFile(m.wikiRecordFile(dbName), content, 0644); err != nil {
+		return err
+	}
+	m.syncFarmIfEnabled()
+	return nil
+}
+
+/*WikiRecords all recorded wikis, keyed by database name*/
+func (m MWDD) WikiRecords() map[string]WikiRecord {
+	records := map[string]WikiRecord{}
+	paths, _ := filepath.Glob(m.wikiRecordFile("*"))
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var record WikiRecord
+		if json.Unmarshal(content, &record) != nil {
+			continue
+		}
+		records[strings.TrimSuffix(filepath.Base(path), ".json")] = record
+	}
+	return records
+}
+
+/*ForgetWikisOfType removes the records of all wikis using the database type, or all wikis for an empty type*/
+func (m MWDD) ForgetWikisOfType(dbType string) {
+	for dbName, record := range m.WikiRecords() {
+		if dbType == "" || record.Type == dbType {
+			os.Remove(m.wikiRecordFile(dbName))
+		}
+	}
+	m.syncFarmIfEnabled()
+}
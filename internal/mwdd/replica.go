@@ -0,0 +1,88 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
+)
+
+const replicaService = "mysql-replica"
+
+// Files written by mysql_connector_main.sh recording where replication starts from
+var replicaPositionFiles = []string{"/mwdd-connector/mysql_position", "/mwdd-connector/mysql_file"}
+
+/*ReplicaStatusFields fields of SHOW SLAVE STATUS that are useful when checking replication, in display order*/
+var ReplicaStatusFields = []string{
+	"Master_Host",
+	"Slave_IO_Running",
+	"Slave_SQL_Running",
+	"Seconds_Behind_Master",
+	"SQL_Delay",
+	"Last_IO_Error",
+	"Last_SQL_Error",
+}
+
+func (m MWDD) replicaSQL(sql string) (string, error) {
+	command, err := m.SQLClientCommand(replicaService, "", sql)
+	if err != nil {
+		return "", err
+	}
+	return m.ExecWithOutput(replicaService, []string{"sh", "-c", strings.Join(command, " ")}, "root")
+}
+
+/*ReplicaStatus the fields of SHOW SLAVE STATUS on the replica, empty if replication is not configured*/
+func (m MWDD) ReplicaStatus() (map[string]string, error) {
+	output, err := m.replicaSQL(`SHOW SLAVE STATUS\G`)
+	if err != nil {
+		return nil, err
+	}
+	return parseVerticalSQLOutput(output), nil
+}
+
+// parseVerticalSQLOutput parses the "Name: value" lines of a single row output with \G
+func parseVerticalSQLOutput(output string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.HasPrefix(strings.TrimSpace(line), "*") {
+			continue
+		}
+		fields[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return fields
+}
+
+/*ResetReplication forgets the recorded replication position and runs the main then replica connectors again*/
+func (m MWDD) ResetReplication(options exec.HandlerOptions) {
+	m.Run("mysql-configure-replication", append([]string{"rm", "-f"}, replicaPositionFiles...), options)
+	m.Run("mysql-configure-replication", []string{}, options)
+	m.Run("mysql-replica-configure-replication", []string{}, options)
+}
+
+/*SetReplicaDelay makes the replica lag behind the main database by the number of seconds, using MASTER_DELAY*/
+func (m MWDD) SetReplicaDelay(seconds int) error {
+	if seconds < 0 {
+		return errors.New("delay must not be negative")
+	}
+	_, err := m.replicaSQL("STOP SLAVE; CHANGE MASTER TO MASTER_DELAY=" + strconv.Itoa(seconds) + "; START SLAVE;")
+	return err
+}
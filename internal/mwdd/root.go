@@ -19,8 +19,10 @@ package mwdd
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/user"
+	"strings"
 
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/exec"
 	"gerrit.wikimedia.org/r/mediawiki/tools/cli/internal/mwdd/files"
@@ -86,6 +88,9 @@ func (m MWDD) Env() dotenv.File {
 func (m MWDD) EnsureReady() {
 	files.EnsureReady(m.Directory())
 	m.Env().EnsureExists()
+	m.ensureRecordsDirectory()
+	m.ensureSettingsDirectory()
+	m.ensureTestResultsDirectory()
 }
 
 // DockerComposeCommand results in something like: `docker-compose <automatic project stuff> <command> <commandArguments>`
@@ -156,6 +161,48 @@ func (m MWDD) ExecNoOutput(service string, commandAndArgs []string, options exec
 	)
 }
 
+/*ExecWithOutput runs `docker-compose exec -T <service> <commandAndArgs>` returning stdout, or an error including stderr*/
+func (m MWDD) ExecWithOutput(service string, commandAndArgs []string, user string) (string, error) {
+	var output string
+	var errorOutput string
+	err := m.DockerCompose(
+		DockerComposeCommand{
+			Command:          "exec",
+			CommandArguments: append([]string{"-T", "--user", user, service}, commandAndArgs...),
+			HandlerOptions: exec.HandlerOptions{
+				HandleStdout: func(stdout bytes.Buffer) { output = stdout.String() },
+				HandleError:  func(stderr bytes.Buffer, err error) { errorOutput = stderr.String() },
+			},
+		},
+	)
+	if err != nil && errorOutput != "" {
+		return output, fmt.Errorf("%v: %s", err, strings.TrimSpace(errorOutput))
+	}
+	return output, err
+}
+
+/*Run runs `docker-compose run --rm --no-deps <service> <commandAndArgs>`, with no command the default of the service is used*/
+func (m MWDD) Run(service string, commandAndArgs []string, options exec.HandlerOptions) {
+	m.DockerComposeTTY(
+		DockerComposeCommand{
+			Command:          "run",
+			CommandArguments: append([]string{"--rm", "--no-deps", service}, commandAndArgs...),
+			HandlerOptions:   options,
+		},
+	)
+}
+
+/*RunAs runs `docker-compose run --rm --no-deps --user <user> <service> <commandAndArgs>`*/
+func (m MWDD) RunAs(service string, user string, commandAndArgs []string, options exec.HandlerOptions) {
+	m.DockerComposeTTY(
+		DockerComposeCommand{
+			Command:          "run",
+			CommandArguments: append([]string{"--rm", "--no-deps", "--user", user, service}, commandAndArgs...),
+			HandlerOptions:   options,
+		},
+	)
+}
+
 /*UpDetached runs `docker-compose up -d <services>`*/
 func (m MWDD) UpDetached(services []string, options exec.HandlerOptions) {
 	m.DockerComposeTTY(
@@ -167,6 +214,17 @@ func (m MWDD) UpDetached(services []string, options exec.HandlerOptions) {
 	)
 }
 
+/*Recreate runs `docker-compose up -d --no-deps --force-recreate <services>`*/
+func (m MWDD) Recreate(services []string, options exec.HandlerOptions) {
+	m.DockerComposeTTY(
+		DockerComposeCommand{
+			Command:          "up",
+			CommandArguments: append([]string{"-d", "--no-deps", "--force-recreate"}, services...),
+			HandlerOptions:   options,
+		},
+	)
+}
+
 /*DownWithVolumesAndOrphans runs `docker-compose down --volumes --remove-orphans`*/
 func (m MWDD) DownWithVolumesAndOrphans(options exec.HandlerOptions) {
 	m.DockerComposeTTY(
@@ -223,8 +281,15 @@ func (m MWDD) RmVolumes(dcVolumes []string, options exec.HandlerOptions) {
 	)
 }
 
+/*CopyFromContainer runs `docker cp <service container>:<source> <destination>`*/
+func (m MWDD) CopyFromContainer(service string, source string, destination string, options exec.HandlerOptions) {
+	exec.RunTTYCommand(
+		options,
+		exec.Command("docker", "cp", m.containerID(service)+":"+source, destination),
+	)
+}
+
 // TODO more from https://github.com/addshore/mediawiki-docker-dev/blob/4d380cf638bc60b5b6c22853a199639a3eb70b0b/control/src/Shell/DockerCompose.php#L53
 // TODO execIt?
-// TODO run?
 // TODO runDetatched?
 // TODO logsTail?
@@ -0,0 +1,86 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*SettingsForAllWikis the settings.d directory loaded for every wiki*/
+const SettingsForAllWikis = "all"
+
+/*SettingsDefaultFile the settings file used when no file name is given*/
+const SettingsDefaultFile = "LocalSettings.php"
+
+/*SettingsDirectory holds PHP files loaded at the end of MwddSettings.php, mounted at /mwdd-settings.d*/
+func (m MWDD) SettingsDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "settings.d"
+}
+
+func (m MWDD) ensureSettingsDirectory() {
+	if err := os.MkdirAll(filepath.Join(m.SettingsDirectory(), SettingsForAllWikis), 0755); err != nil {
+		panic(err)
+	}
+}
+
+/*SettingsFile the path of a settings file for a wiki, or for all wikis if the wiki is empty*/
+func (m MWDD) SettingsFile(wiki string, file string) (string, error) {
+	if wiki == "" {
+		wiki = SettingsForAllWikis
+	}
+	if file == "" {
+		file = SettingsDefaultFile
+	}
+	if !strings.HasSuffix(file, ".php") {
+		file += ".php"
+	}
+	for _, name := range []string{wiki, file} {
+		if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return "", errors.New("invalid name " + name)
+		}
+	}
+	return filepath.Join(m.SettingsDirectory(), wiki, file), nil
+}
+
+/*SettingsFiles the settings files that apply to a wiki, in the order that they are loaded*/
+func (m MWDD) SettingsFiles(wiki string) []string {
+	files, _ := filepath.Glob(filepath.Join(m.SettingsDirectory(), SettingsForAllWikis, "*.php"))
+	if wiki != SettingsForAllWikis {
+		wikiFiles, _ := filepath.Glob(filepath.Join(m.SettingsDirectory(), wiki, "*.php"))
+		files = append(files, wikiFiles...)
+	}
+	return files
+}
+
+/*SettingsWikis the wikis that have their own settings directory*/
+func (m MWDD) SettingsWikis() []string {
+	wikis := []string{}
+	entries, _ := ioutil.ReadDir(m.SettingsDirectory())
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != SettingsForAllWikis {
+			wikis = append(wikis, entry.Name())
+		}
+	}
+	sort.Strings(wikis)
+	return wikis
+}
@@ -0,0 +1,155 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+/*EnvironmentStatus what the CLI knows about the environment, written for Special:Mwdd to read*/
+type EnvironmentStatus struct {
+	CLIVersion string            `json:"cliVersion"`
+	Generated  string            `json:"generated"`
+	Services   []string          `json:"services"`
+	Links      map[string]string `json:"links"`
+	// Host ports only, the status is readable by anything that can reach the wikis
+	ExposedPorts map[string]string `json:"exposedPorts"`
+	HTTPS        bool              `json:"https"`
+	XdebugMode   string            `json:"xdebugMode"`
+}
+
+// Services with a web interface, and the host that they are served on
+var statusLinkHosts = map[string]string{
+	"adminer":    "adminer." + HostSuffix,
+	"phpmyadmin": "phpmyadmin." + HostSuffix,
+	"graphite":   "graphite." + HostSuffix,
+}
+
+/*RunningServices the docker-compose services of the environment that currently have a running container*/
+func (m MWDD) RunningServices() ([]string, error) {
+	cli, err := dockerClient()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+m.DockerComposeProjectName())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	services := []string{}
+	for _, container := range containers {
+		if service, ok := container.Labels["com.docker.compose.service"]; ok {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+/*Status collects the status of the environment from the point of view of the CLI*/
+func (m MWDD) Status(cliVersion string) EnvironmentStatus {
+	services, _ := m.RunningServices()
+	status := EnvironmentStatus{
+		CLIVersion:   cliVersion,
+		Generated:    time.Now().UTC().Format(time.RFC3339),
+		Services:     services,
+		Links:        map[string]string{},
+		ExposedPorts: map[string]string{},
+		HTTPS:        m.HTTPSEnabled(),
+		XdebugMode:   m.XdebugMode(),
+	}
+	for _, service := range services {
+		if host, ok := statusLinkHosts[service]; ok {
+			status.Links[service] = m.HostURL(host)
+		}
+		if port := m.ExposedPort(service); port != "" {
+			status.ExposedPorts[service] = port
+		}
+	}
+	return status
+}
+
+func (m MWDD) statusFile() string {
+	return m.recordsDirectory() + string(os.PathSeparator) + "status.json"
+}
+
+/*WriteStatus writes the status of the environment to the records directory, where Special:Mwdd reads it*/
+func (m MWDD) WriteStatus(cliVersion string) error {
+	content, err := json.MarshalIndent(m.Status(cliVersion), "", "  ")
+	if err != nil {
+		return err
+	}
+	m.ensureRecordsDirectory()
+	return ioutil.WriteFile(m.statusFile(), content, 0644)
+}
+
+// wikiGet requests a path of a wiki through the proxy on localhost
+func (m MWDD) wikiGet(wiki string, path string) (*http.Response, error) {
+	request, err := http.NewRequest("GET", "http://127.0.0.1:"+m.Env().Get("PORT")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The proxy picks the service from the host, which may not resolve on this machine
+	request.Host = wiki + ".mediawiki." + HostSuffix
+
+	client := http.Client{Timeout: 30 * time.Second}
+	return client.Do(request)
+}
+
+/*WikiResponseCode the HTTP status code of the main page of a wiki*/
+func (m MWDD) WikiResponseCode(wiki string) (int, error) {
+	response, err := m.wikiGet(wiki, "/w/index.php")
+	if err != nil {
+		return 0, err
+	}
+	response.Body.Close()
+	return response.StatusCode, nil
+}
+
+/*WikiStatus fetches the JSON output of Special:Mwdd for a wiki, through the proxy on the host*/
+func (m MWDD) WikiStatus(wiki string) (map[string]interface{}, error) {
+	response, err := m.wikiGet(wiki, "/w/index.php?title=Special:Mwdd/json")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("Special:Mwdd responded with " + response.Status)
+	}
+
+	var status map[string]interface{}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, errors.New("Special:Mwdd did not respond with JSON, is the wiki installed?")
+	}
+	return status, nil
+}
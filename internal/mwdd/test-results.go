@@ -0,0 +1,36 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"os"
+)
+
+/*TestResultsContainerDirectory where the test results directory is mounted in the mediawiki and mediawiki-browser containers*/
+const TestResultsContainerDirectory = "/mwdd-test-results"
+
+/*TestResultsDirectory the directory that test commands write JUnit XML results to*/
+func (m MWDD) TestResultsDirectory() string {
+	return m.Directory() + string(os.PathSeparator) + "test-results"
+}
+
+func (m MWDD) ensureTestResultsDirectory() {
+	if err := os.MkdirAll(m.TestResultsDirectory(), 0755); err != nil {
+		panic(err)
+	}
+}
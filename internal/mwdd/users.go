@@ -0,0 +1,73 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*WikiUser an account to create on a wiki*/
+type WikiUser struct {
+	Name     string
+	Password string
+	Groups   []string
+}
+
+/*ParseGroups splits groups separated by commas, semicolons or spaces*/
+func ParseGroups(groups string) []string {
+	return strings.FieldsFunc(groups, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	})
+}
+
+/*ParseWikiUsersCSV reads users from rows of name,password,groups where the password and groups are optional, skipping a header row*/
+func ParseWikiUsersCSV(reader io.Reader) ([]WikiUser, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	users := []WikiUser{}
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "name") {
+			continue
+		}
+		if len(record) > 3 {
+			return nil, errors.New("line " + strconv.Itoa(i+1) + " has more than name,password,groups")
+		}
+		user := WikiUser{Name: strings.TrimSpace(record[0])}
+		if user.Name == "" {
+			return nil, errors.New("line " + strconv.Itoa(i+1) + " has no user name")
+		}
+		if len(record) > 1 {
+			user.Password = record[1]
+		}
+		if len(record) > 2 {
+			user.Groups = ParseGroups(record[2])
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
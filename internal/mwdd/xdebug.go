@@ -0,0 +1,73 @@
+/*Package mwdd is used to interact a mwdd v2 setup
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package mwdd
+
+import (
+	"runtime"
+	"strings"
+)
+
+/*XdebugOutputDirectory directory in the mediawiki container that profile and trace files are written to*/
+const XdebugOutputDirectory = "/var/log/mediawiki/xdebug"
+
+/*XdebugDefaultMode the xdebug mode used by mediawiki.yml when MEDIAWIKI_XDEBUG_MODE is not set*/
+const XdebugDefaultMode = "develop,debug"
+
+/*XdebugModes maps the mode names used by the CLI to values for XDEBUG_MODE*/
+var XdebugModes = map[string]string{
+	"on":      XdebugDefaultMode,
+	"off":     "off",
+	"profile": "profile",
+	"trace":   "trace",
+}
+
+/*XdebugClientHost the host that xdebug in a container should connect back to for the current host OS*/
+func (m MWDD) XdebugClientHost() string {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return "host.docker.internal"
+	}
+	// On Linux the gateway of the environment network is the host
+	return m.NetworkGateway()
+}
+
+/*XdebugConfig builds a value for XDEBUG_CONFIG suitable for the given XDEBUG_MODE*/
+func (m MWDD) XdebugConfig(mode string) string {
+	config := []string{
+		"client_host=" + m.XdebugClientHost(),
+		"output_dir=" + XdebugOutputDirectory,
+	}
+	// Profiling and tracing every request is slow, so only start when the XDEBUG_TRIGGER is used
+	if strings.Contains(mode, "profile") || strings.Contains(mode, "trace") {
+		config = append(config, "start_with_request=trigger")
+	}
+	return strings.Join(config, " ")
+}
+
+/*XdebugMode the currently configured XDEBUG_MODE for the mediawiki service*/
+func (m MWDD) XdebugMode() string {
+	if m.Env().Has("MEDIAWIKI_XDEBUG_MODE") {
+		return m.Env().Get("MEDIAWIKI_XDEBUG_MODE")
+	}
+	return XdebugDefaultMode
+}
+
+/*SetXdebugMode records the XDEBUG_MODE and a matching XDEBUG_CONFIG in the env file*/
+func (m MWDD) SetXdebugMode(mode string) {
+	m.Env().Set("MEDIAWIKI_XDEBUG_MODE", mode)
+	m.Env().Set("MEDIAWIKI_XDEBUG_CONFIG", m.XdebugConfig(mode))
+}
@@ -0,0 +1,132 @@
+/*Package archive in internal utils is functionality for streaming compressed files and directories
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Produced by `printf 'hello world\n' | bzip2`, as the standard library can only decompress bzip2
+var bzip2HelloWorld = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x4e, 0xec, 0xe8, 0x36, 0x00, 0x00,
+	0x02, 0x51, 0x80, 0x00, 0x10, 0x40, 0x00, 0x06, 0x44, 0x90, 0x80, 0x20, 0x00, 0x31, 0x06, 0x4c,
+	0x41, 0x01, 0xa7, 0xa9, 0xa5, 0x80, 0xbb, 0x94, 0x31, 0xf8, 0xbb, 0x92, 0x29, 0xc2, 0x84, 0x82,
+	0x77, 0x67, 0x41, 0xb0,
+}
+
+func gzipped(content string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	writer.Write([]byte(content))
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func writeTmpFile(content []byte) string {
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "mwcli-test-archive-")
+	if err != nil {
+		panic(err)
+	}
+	tmpFile.Write(content)
+	tmpFile.Close()
+	return tmpFile.Name()
+}
+
+func TestOpenDecompressed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "plain", content: []byte("hello world\n")},
+		{name: "gzip", content: gzipped("hello world\n")},
+		{name: "bzip2", content: bzip2HelloWorld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTmpFile(tt.content)
+			defer os.Remove(path)
+
+			reader, err := OpenDecompressed(path)
+			if err != nil {
+				t.Fatalf("OpenDecompressed() unexpected error %v", err)
+			}
+			defer reader.Close()
+			got, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("reading unexpected error %v", err)
+			}
+			if string(got) != "hello world\n" {
+				t.Errorf("OpenDecompressed() read %q", got)
+			}
+		})
+	}
+}
+
+func TestOpenDecompressedShortFile(t *testing.T) {
+	path := writeTmpFile([]byte("a"))
+	defer os.Remove(path)
+
+	reader, err := OpenDecompressed(path)
+	if err != nil {
+		t.Fatalf("OpenDecompressed() unexpected error %v", err)
+	}
+	defer reader.Close()
+	if got, _ := ioutil.ReadAll(reader); string(got) != "a" {
+		t.Errorf("OpenDecompressed() read %q", got)
+	}
+}
+
+func TestTarDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir(os.TempDir(), "mwcli-test-archive-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(directory)
+	os.MkdirAll(filepath.Join(directory, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(directory, "a.png"), []byte("a"), 0644)
+	ioutil.WriteFile(filepath.Join(directory, "sub", "b.jpg"), []byte("bb"), 0644)
+
+	var buffer bytes.Buffer
+	if err := TarDirectory(directory, &buffer); err != nil {
+		t.Fatalf("TarDirectory() unexpected error %v", err)
+	}
+
+	got := map[string]string{}
+	reader := tar.NewReader(&buffer)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar unexpected error %v", err)
+		}
+		content, _ := ioutil.ReadAll(reader)
+		got[header.Name] = string(content)
+	}
+	if len(got) != 2 || got["a.png"] != "a" || got["sub/b.jpg"] != "bb" {
+		t.Errorf("TarDirectory() wrote %v", got)
+	}
+}
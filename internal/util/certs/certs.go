@@ -0,0 +1,126 @@
+/*Package certs in internal utils is functionality for generating a local certificate authority and certificates
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package certs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+var keyBits = 2048
+var caValidity = 10 * 365 * 24 * time.Hour
+
+// Browsers reject leaf certificates that are valid for longer than 825 days
+var certValidity = 800 * 24 * time.Hour
+
+/*Pair a PEM encoded certificate and private key*/
+type Pair struct {
+	Certificate []byte
+	Key         []byte
+}
+
+/*GenerateCA creates a new self signed certificate authority*/
+func GenerateCA(commonName string) (Pair, error) {
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{commonName},
+		},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		MaxPathLenZero:        true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	return generate(template, nil, nil, caValidity)
+}
+
+/*GenerateSigned creates a new certificate for the hosts, signed by the certificate authority*/
+func GenerateSigned(ca Pair, hosts []string) (Pair, error) {
+	if len(hosts) == 0 {
+		return Pair{}, errors.New("at least one host is required")
+	}
+	caCert, caKey, err := parse(ca)
+	if err != nil {
+		return Pair{}, err
+	}
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   hosts[0],
+			Organization: caCert.Subject.Organization,
+		},
+		DNSNames:    hosts,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return generate(template, caCert, caKey, certValidity)
+}
+
+func generate(template *x509.Certificate, parent *x509.Certificate, parentKey *rsa.PrivateKey, validity time.Duration) (Pair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return Pair{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return Pair{}, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(validity)
+
+	// Self sign when there is no parent
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:         pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}, nil
+}
+
+func parse(pair Pair) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(pair.Certificate)
+	if certBlock == nil {
+		return nil, nil, errors.New("unable to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(pair.Key)
+	if keyBlock == nil {
+		return nil, nil, errors.New("unable to decode key PEM")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
@@ -0,0 +1,84 @@
+/*Package certs in internal utils is functionality for generating a local certificate authority and certificates
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestGenerateSigned(t *testing.T) {
+	// Smaller keys keep the test fast
+	keyBits = 1024
+
+	ca, err := GenerateCA("mwcli test CA")
+	if err != nil {
+		t.Fatalf("GenerateCA() error = %v", err)
+	}
+	cert, err := GenerateSigned(ca, []string{"mwcli.test", "*.mwcli.test"})
+	if err != nil {
+		t.Fatalf("GenerateSigned() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca.Certificate) {
+		t.Fatalf("CA certificate could not be loaded")
+	}
+	block, _ := pem.Decode(cert.Certificate)
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Certificate could not be parsed: %v", err)
+	}
+
+	tests := []struct {
+		host  string
+		valid bool
+	}{
+		{host: "mwcli.test", valid: true},
+		{host: "foo.mwcli.test", valid: true},
+		{host: "foo.bar.mwcli.test", valid: false},
+		{host: "example.com", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			_, err := parsed.Verify(x509.VerifyOptions{
+				DNSName: tt.host,
+				Roots:   roots,
+			})
+			if tt.valid && err != nil {
+				t.Errorf("Verify() unexpected error %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Verify() expected an error")
+			}
+		})
+	}
+}
+
+func TestGenerateSigned_NoHosts(t *testing.T) {
+	keyBits = 1024
+
+	ca, err := GenerateCA("mwcli test CA")
+	if err != nil {
+		t.Fatalf("GenerateCA() error = %v", err)
+	}
+	if _, err := GenerateSigned(ca, []string{}); err == nil {
+		t.Errorf("GenerateSigned() expected an error")
+	}
+}
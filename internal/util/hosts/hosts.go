@@ -20,87 +20,183 @@ package hosts
 import (
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
-
-	"github.com/txn2/txeh"
 )
 
 var hostsFile = ""
 var hostsTmpPrefix = "mwcli-hosts-"
 
+const blockBegin = "# BEGIN mwcli"
+const blockEnd = "# END mwcli"
+const blockComment = "# Managed by mwcli, changes within this block will be overwritten"
+const hostAddress = "127.0.0.1"
+const backupSuffix = ".mwcli-backup"
+
 /*Save result of saving the hosts file*/
 type Save struct {
-	Success bool
-	Content string
-	TmpFile string
+	Success    bool
+	Content    string
+	TmpFile    string
+	BackupFile string
 }
 
-/*AddHosts attempts to add requested hosts to the system hosts file, and gives you the new content, a tmp file and success*/
+/*AddHosts attempts to add requested hosts to the mwcli block of the system hosts file, and gives you the new content, a tmp file and success*/
 func AddHosts(toAdd []string) Save {
-	hosts := hosts()
-	hosts.AddHosts("127.0.0.1", toAdd)
-	return save(hosts)
+	content := read()
+	return save(content, withBlockHosts(content, append(blockHosts(content), toAdd...)))
 }
 
-/*RemoveHostsWithSuffix attempts to remove all hosts with a suffix from the system hosts file, and gives you the new content, a tmp file and success*/
-func RemoveHostsWithSuffix(hostSuffix string) Save {
-	hosts := hosts()
-	removeHostsWithSuffixFromLines(hostSuffix, hosts)
-	return save(hosts)
+/*SyncHosts attempts to make the mwcli block of the system hosts file contain exactly the wanted hosts, adding missing and removing stale entries in a single write*/
+func SyncHosts(wanted []string) Save {
+	content := read()
+	return save(content, withBlockHosts(content, wanted))
 }
 
-func tmpFile() string {
-	tmpFile, err := ioutil.TempFile(os.TempDir(), hostsTmpPrefix)
+/*RemoveHosts attempts to remove the mwcli block from the system hosts file, and gives you the new content, a tmp file and success*/
+func RemoveHosts() Save {
+	content := read()
+	return save(content, withBlockHosts(content, []string{}))
+}
+
+/*SaveWithSudo copies the tmp file of a failed save into place using sudo, keeping a backup of the previous hosts file*/
+func SaveWithSudo(failed Save) error {
+	if runtime.GOOS == "windows" {
+		return &os.PathError{Op: "sudo", Path: filePath(), Err: os.ErrPermission}
+	}
+	if err := sudo("cp", filePath(), failed.BackupFile); err != nil {
+		return err
+	}
+	return sudo("cp", failed.TmpFile, filePath())
+}
+
+func sudo(args ...string) error {
+	cmd := exec.Command("sudo", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func filePath() string {
+	if hostsFile != "" {
+		return hostsFile
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+func read() string {
+	content, err := ioutil.ReadFile(filePath())
 	if err != nil {
 		panic(err)
 	}
-	tmpFile.Close()
-	return tmpFile.Name()
+	return string(content)
 }
 
-func hosts() *txeh.Hosts {
-	hosts, err := txeh.NewHosts(&txeh.HostsConfig{
-		ReadFilePath:  hostsFile,
-		WriteFilePath: hostsFile,
-	})
+func tmpFile(content string) string {
+	tmpFile, err := ioutil.TempFile(os.TempDir(), hostsTmpPrefix)
 	if err != nil {
 		panic(err)
 	}
+	defer tmpFile.Close()
+	if _, err := tmpFile.WriteString(content); err != nil {
+		panic(err)
+	}
+	return tmpFile.Name()
+}
+
+// splitBlock returns the content before and after the mwcli block, and the lines within it
+func splitBlock(content string) (before string, block []string, after string) {
+	lines := strings.SplitAfter(content, "\n")
+	begin, end := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == blockBegin && begin == -1 {
+			begin = i
+		}
+		if trimmed == blockEnd && begin != -1 {
+			end = i
+			break
+		}
+	}
+	if begin == -1 || end == -1 {
+		return content, []string{}, ""
+	}
+	for _, line := range lines[begin+1 : end] {
+		block = append(block, strings.TrimSpace(line))
+	}
+	return strings.Join(lines[:begin], ""), block, strings.Join(lines[end+1:], "")
+}
+
+func blockHosts(content string) []string {
+	_, block, _ := splitBlock(content)
+	hosts := []string{}
+	for _, line := range block {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, strings.Fields(line)[1:]...)
+	}
 	return hosts
 }
 
-func removeHostsWithSuffixFromLines(hostSuffix string, hosts *txeh.Hosts) *txeh.Hosts {
-	lines := hosts.GetHostFileLines()
+func withBlockHosts(content string, hosts []string) string {
+	before, _, after := splitBlock(content)
 
-	for _, line := range *lines {
-		for _, hostname := range line.Hostnames {
-			if strings.HasSuffix(hostname, hostSuffix) {
-				hosts.RemoveHost(hostname)
-			}
+	seen := map[string]bool{}
+	block := ""
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || seen[host] {
+			continue
 		}
+		seen[host] = true
+		block += hostAddress + " " + host + "\n"
 	}
 
-	return hosts
+	if block == "" {
+		if after == "" && before != "" {
+			// Drop the blank line that separated the block from the rest of the file
+			before = strings.TrimRight(before, "\n") + "\n"
+		}
+		return before + after
+	}
+
+	// A new block is appended to the end of the file, separated by a blank line
+	if before != "" && after == "" && !strings.Contains(content, blockBegin) {
+		before = strings.TrimRight(before, "\n") + "\n\n"
+	}
+	return before + blockBegin + "\n" + blockComment + "\n" + block + blockEnd + "\n" + after
 }
 
-func save(hosts *txeh.Hosts) Save {
-	err := hosts.Save()
+func save(oldContent string, newContent string) Save {
+	backupFile := filePath() + backupSuffix
+	if oldContent == newContent {
+		return Save{Success: true, Content: newContent, TmpFile: filePath(), BackupFile: ""}
+	}
+
+	err := ioutil.WriteFile(backupFile, []byte(oldContent), 0644)
+	if err == nil {
+		err = ioutil.WriteFile(filePath(), []byte(newContent), 0644)
+	}
 	if err != nil {
-		tmpFile := tmpFile()
-		err = hosts.SaveAs(tmpFile)
-		if err != nil {
-			panic(err)
-		}
 		return Save{
-			Success: false,
-			Content: hosts.RenderHostsFile(),
-			TmpFile: tmpFile,
+			Success:    false,
+			Content:    newContent,
+			TmpFile:    tmpFile(newContent),
+			BackupFile: backupFile,
 		}
 	}
 
 	return Save{
-		Success: true,
-		Content: hosts.RenderHostsFile(),
-		TmpFile: hosts.WriteFilePath,
+		Success:    true,
+		Content:    newContent,
+		TmpFile:    filePath(),
+		BackupFile: backupFile,
 	}
 }
@@ -26,8 +26,9 @@ import (
 
 var singleLocalHost = "127.0.0.1        iam.localhost\n"
 var singleOtherHost = "123.123.111.111        iam.not.localhost\n"
-var twoHostsToRemoveOnly = "127.0.0.1        1.mwcli.test 2.mwcli.test\n"
-var twoHostsToRemoveFromLocal = "127.0.0.1        iam.localhost 1.mwcli.test 2.mwcli.test\n"
+var handAddedHost = "127.0.0.1        iam.localhost 1.mwcli.test\n"
+var blockWithTwoHosts = "# BEGIN mwcli\n" + blockComment + "\n127.0.0.1 1.mwcli.test\n127.0.0.1 2.mwcli.test\n# END mwcli\n"
+var blockWithOneHost = "# BEGIN mwcli\n" + blockComment + "\n127.0.0.1 2.mwcli.test\n# END mwcli\n"
 
 func writeContentToTmpFile(content string) string {
 	tmpFile, err := ioutil.TempFile(os.TempDir(), hostsTmpPrefix+"test-")
@@ -39,138 +40,150 @@ func writeContentToTmpFile(content string) string {
 	return tmpFile.Name()
 }
 
-func TestAddHosts(t *testing.T) {
-	type args struct {
-		toAdd []string
+func runSaveTest(t *testing.T, startingContent string, want Save, changed bool, do func() Save) {
+	// Setup a test file
+	testFile := writeContentToTmpFile(startingContent)
+	hostsFile = testFile
+	want.TmpFile = testFile
+	if changed {
+		want.BackupFile = testFile + backupSuffix
+		defer os.Remove(want.BackupFile)
+	}
+
+	// Perform the test!
+	if got := do(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	written, _ := ioutil.ReadFile(testFile)
+	if string(written) != want.Content {
+		t.Errorf("written content = %q, want %q", string(written), want.Content)
+	}
+	if changed {
+		backup, _ := ioutil.ReadFile(want.BackupFile)
+		if string(backup) != startingContent {
+			t.Errorf("backup content = %q, want %q", string(backup), startingContent)
+		}
 	}
+}
+
+func TestAddHosts(t *testing.T) {
 	tests := []struct {
-		startingContent string
 		name            string
-		args            args
-		want            Save
+		startingContent string
+		toAdd           []string
+		wantContent     string
+		changed         bool
 	}{
 		{
-			name:            "Empty: Add single: 1.mwcli.test",
+			name:            "Empty: Add two",
 			startingContent: "",
-			args: args{
-				toAdd: []string{"1.mwcli.test"},
-			},
-			want: Save{
-				Success: true,
-				Content: "127.0.0.1        1.mwcli.test\n",
-				TmpFile: "",
-			},
+			toAdd:           []string{"1.mwcli.test", "2.mwcli.test"},
+			wantContent:     blockWithTwoHosts,
+			changed:         true,
 		},
 		{
-			name:            "Empty: Add two: 1.mwcli.test, 2.mwcli.test",
-			startingContent: "",
-			args: args{
-				toAdd: []string{"1.mwcli.test", "2.mwcli.test"},
-			},
-			want: Save{
-				Success: true,
-				Content: "127.0.0.1        1.mwcli.test 2.mwcli.test\n",
-				TmpFile: "",
-			},
+			name:            "singleOtherHost: Add two",
+			startingContent: singleOtherHost,
+			toAdd:           []string{"1.mwcli.test", "2.mwcli.test"},
+			wantContent:     singleOtherHost + "\n" + blockWithTwoHosts,
+			changed:         true,
 		},
 		{
-			name:            "singleLocalHost: Add two: 1.mwcli.test, 2.mwcli.test",
-			startingContent: singleLocalHost,
-			args: args{
-				toAdd: []string{"1.mwcli.test", "2.mwcli.test"},
-			},
-			want: Save{
-				Success: true,
-				Content: "127.0.0.1        iam.localhost 1.mwcli.test 2.mwcli.test\n",
-				TmpFile: "",
-			},
+			name:            "Existing block: Add one more",
+			startingContent: singleLocalHost + "\n" + blockWithOneHost,
+			toAdd:           []string{"1.mwcli.test"},
+			wantContent:     singleLocalHost + "\n# BEGIN mwcli\n" + blockComment + "\n127.0.0.1 2.mwcli.test\n127.0.0.1 1.mwcli.test\n# END mwcli\n",
+			changed:         true,
 		},
 		{
-			name:            "singleOtherHost: Add two: 1.mwcli.test, 2.mwcli.test",
-			startingContent: singleOtherHost,
-			args: args{
-				toAdd: []string{"1.mwcli.test", "2.mwcli.test"},
-			},
-			want: Save{
-				Success: true,
-				Content: "123.123.111.111  iam.not.localhost\n127.0.0.1        1.mwcli.test 2.mwcli.test\n",
-				TmpFile: "",
-			},
+			name:            "Existing block: Add already present",
+			startingContent: singleLocalHost + "\n" + blockWithTwoHosts,
+			toAdd:           []string{"2.mwcli.test"},
+			wantContent:     singleLocalHost + "\n" + blockWithTwoHosts,
+			changed:         false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup a test file
-			testFile := writeContentToTmpFile(tt.startingContent)
-			hostsFile = testFile
-			tt.want.TmpFile = testFile
-
-			// Perform the test!
-			if got := AddHosts(tt.args.toAdd); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("AddHosts() = %v, want %v", got, tt.want)
-			}
+			runSaveTest(t, tt.startingContent, Save{Success: true, Content: tt.wantContent}, tt.changed, func() Save {
+				return AddHosts(tt.toAdd)
+			})
 		})
 	}
 }
 
-func TestRemoveHostsWithSuffix(t *testing.T) {
-	type args struct {
-		hostSuffix string
-	}
+func TestSyncHosts(t *testing.T) {
 	tests := []struct {
-		startingContent string
 		name            string
-		args            args
-		want            Save
+		startingContent string
+		wanted          []string
+		wantContent     string
 	}{
 		{
-			name:            "Remove mwcli.test suffix, resulting in same content",
-			startingContent: singleLocalHost,
-			args: args{
-				hostSuffix: "mwcli.test",
-			},
-			want: Save{
-				Success: true,
-				Content: singleLocalHost,
-				TmpFile: "",
-			},
+			name:            "Empty: Sync two",
+			startingContent: "",
+			wanted:          []string{"1.mwcli.test", "2.mwcli.test"},
+			wantContent:     blockWithTwoHosts,
 		},
 		{
-			name:            "Remove mwcli.test suffix, removing 2, resulting in nothing",
-			startingContent: twoHostsToRemoveOnly,
-			args: args{
-				hostSuffix: "mwcli.test",
-			},
-			want: Save{
-				Success: true,
-				Content: "",
-				TmpFile: "",
-			},
+			name:            "Block in the middle: Sync one, removing the other",
+			startingContent: singleLocalHost + blockWithTwoHosts + singleOtherHost,
+			wanted:          []string{"2.mwcli.test"},
+			wantContent:     singleLocalHost + blockWithOneHost + singleOtherHost,
 		},
 		{
-			name:            "Remove mwcli.test suffix, removing 2, resulting in 1 left",
-			startingContent: twoHostsToRemoveFromLocal,
-			args: args{
-				hostSuffix: "mwcli.test",
-			},
-			want: Save{
-				Success: true,
-				Content: singleLocalHost,
-				TmpFile: "",
-			},
+			name:            "Hand added host outside of the block is left alone",
+			startingContent: handAddedHost + "\n" + blockWithTwoHosts,
+			wanted:          []string{"2.mwcli.test"},
+			wantContent:     handAddedHost + "\n" + blockWithOneHost,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup a test file
-			testFile := writeContentToTmpFile(tt.startingContent)
-			hostsFile = testFile
-			tt.want.TmpFile = testFile
+			runSaveTest(t, tt.startingContent, Save{Success: true, Content: tt.wantContent}, true, func() Save {
+				return SyncHosts(tt.wanted)
+			})
+		})
+	}
+}
 
-			// Perform the test!
-			if got := RemoveHostsWithSuffix(tt.args.hostSuffix); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("RemoveHostsWithSuffix() = %v, want %v", got, tt.want)
-			}
+func TestRemoveHosts(t *testing.T) {
+	tests := []struct {
+		name            string
+		startingContent string
+		wantContent     string
+		changed         bool
+	}{
+		{
+			name:            "No block, resulting in same content",
+			startingContent: handAddedHost,
+			wantContent:     handAddedHost,
+			changed:         false,
+		},
+		{
+			name:            "Only a block, resulting in nothing",
+			startingContent: blockWithTwoHosts,
+			wantContent:     "",
+			changed:         true,
+		},
+		{
+			name:            "Block at the end, leaving hand added hosts",
+			startingContent: handAddedHost + "\n" + blockWithTwoHosts,
+			wantContent:     handAddedHost,
+			changed:         true,
+		},
+		{
+			name:            "Block in the middle",
+			startingContent: singleLocalHost + blockWithTwoHosts + singleOtherHost,
+			wantContent:     singleLocalHost + singleOtherHost,
+			changed:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSaveTest(t, tt.startingContent, Save{Success: true, Content: tt.wantContent}, tt.changed, func() Save {
+				return RemoveHosts()
+			})
 		})
 	}
 }
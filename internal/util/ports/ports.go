@@ -19,64 +19,131 @@ package ports
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"strconv"
 )
 
+/*AllInterfaces bind address that checks ports in the same way docker publishes them by default*/
+const AllInterfaces = ""
+
+/*Localhost bind address for ports that are only published to the local machine*/
+const Localhost = "127.0.0.1"
+
 var defaultStartingPort = "8080"
-var portSearchLoopsBeforePanic = 25
+var portSearchSize = 25
 
-/*FreeUpFrom get a free port up from and including the startingPort. Will attempt 25 ports.*/
-func FreeUpFrom(startingPort string) string {
-	if isValid(startingPort) != nil {
-		startingPort = defaultStartingPort
+/*Range of ports to search, inclusive of both ends*/
+type Range struct {
+	From int
+	To   int
+}
+
+/*RangeFrom a Range of the default search size starting at and including the startingPort*/
+func RangeFrom(startingPort string) Range {
+	from, err := parse(startingPort)
+	if err != nil {
+		from, _ = parse(defaultStartingPort)
 	}
+	to := from + portSearchSize - 1
+	if to > 65535 {
+		to = 65535
+	}
+	return Range{From: from, To: to}
+}
 
-	portFloat, _ := strconv.ParseFloat(startingPort, 64)
-	loops := 1
+/*FreeUpFrom get a free port on all interfaces up from and including the startingPort*/
+func FreeUpFrom(startingPort string) (string, error) {
+	return FreeInRange(AllInterfaces, RangeFrom(startingPort))
+}
 
-	for portFloat < 65535 && loops <= portSearchLoopsBeforePanic {
-		loopPort := strconv.FormatFloat(portFloat, 'f', 0, 64)
-		if IsValidAndFree(loopPort) == nil {
-			return loopPort
-		}
-		portFloat++
-		loops++
+/*FreeInRange get the first port in the range that is free on the bind address*/
+func FreeInRange(address string, portRange Range) (string, error) {
+	free, err := FreeSet(address, []Range{portRange}, []string{})
+	if err != nil {
+		return "", err
 	}
+	return free[0], nil
+}
 
-	panic("Error: Too many loops finding free port")
+/*FreeSet get a free port from each range on the bind address, never returning the same port twice or any port that is already taken*/
+func FreeSet(address string, ranges []Range, taken []string) ([]string, error) {
+	excluded := map[string]bool{}
+	for _, port := range taken {
+		excluded[port] = true
+	}
+
+	// Listeners are kept open until the whole set is found, so that the ports are free at the same time
+	listeners := []net.Listener{}
+	defer func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}()
+
+	free := []string{}
+	for _, portRange := range ranges {
+		if portRange.From < 1 || portRange.To > 65535 || portRange.From > portRange.To {
+			return nil, fmt.Errorf("invalid port range %d-%d", portRange.From, portRange.To)
+		}
+		found := ""
+		for port := portRange.From; port <= portRange.To && found == ""; port++ {
+			candidate := strconv.Itoa(port)
+			if excluded[candidate] {
+				continue
+			}
+			ln, err := listen(address, candidate)
+			if err != nil {
+				continue
+			}
+			listeners = append(listeners, ln)
+			excluded[candidate] = true
+			found = candidate
+		}
+		if found == "" {
+			return nil, fmt.Errorf("no free port between %d and %d", portRange.From, portRange.To)
+		}
+		free = append(free, found)
+	}
+	return free, nil
 }
 
-/*IsValidAndFree ...*/
+/*IsValidAndFree is the port valid and free on all interfaces*/
 func IsValidAndFree(port string) error {
+	return IsValidAndFreeOn(AllInterfaces, port)
+}
+
+/*IsValidAndFreeOn is the port valid and free on the bind address*/
+func IsValidAndFreeOn(address string, port string) error {
 	err := isValid(port)
 	if err != nil {
 		return err
 	}
-	err = isFree(port)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func isFree(port string) error {
-	ln, err := net.Listen("tcp", ":"+port)
+	ln, err := listen(address, port)
 	if err != nil {
 		return errors.New("port is not available to listen on")
 	}
-
 	ln.Close()
 	return nil
 }
 
-func isValid(port string) error {
-	parsedPort, err := strconv.ParseFloat(port, 64)
+// listen works for IPv4 and IPv6 bind addresses, as JoinHostPort adds the brackets needed for IPv6
+func listen(address string, port string) (net.Listener, error) {
+	return net.Listen("tcp", net.JoinHostPort(address, port))
+}
+
+func parse(port string) (int, error) {
+	parsedPort, err := strconv.Atoi(port)
 	if err != nil {
-		return errors.New("invalid number")
+		return 0, errors.New("invalid number")
 	}
 	if parsedPort > 65535 || parsedPort < 1 {
-		return errors.New("invalid port number")
+		return 0, errors.New("invalid port number")
 	}
-	return nil
+	return parsedPort, nil
+}
+
+func isValid(port string) error {
+	_, err := parse(port)
+	return err
 }
@@ -2,48 +2,94 @@ package ports
 
 import (
 	"net"
+	"strconv"
 	"testing"
 
 	"github.com/alecthomas/assert"
 )
 
+// bind listens on a port, the returned listener must be closed by the test
+func bind(t *testing.T, address string, port string) net.Listener {
+	ln, err := net.Listen("tcp", net.JoinHostPort(address, port))
+	if err != nil {
+		t.Skipf("Could not bind %s for the test: %v", port, err)
+	}
+	return ln
+}
+
 func Test_FreeUpFrom(t *testing.T) {
 
 	type test struct {
-		loopLimit     int
+		name          string
+		searchSize    int
 		requestedPort string
 		boundPort     string
 		resultingPort string
+		wantErr       bool
 	}
 
 	defaultStartingPort = "56664"
 
 	tests := []test{
 		// 56665 is only probably free...
-		{loopLimit: 25, requestedPort: "56665", boundPort: "", resultingPort: "56665"},
-		{loopLimit: 25, requestedPort: "56665", boundPort: "56665", resultingPort: "56666"},
-		{loopLimit: 25, requestedPort: "default to be used", boundPort: "", resultingPort: "56664"},
-		{loopLimit: 1, requestedPort: "default to be used", boundPort: defaultStartingPort, resultingPort: "panic"},
+		{name: "free", searchSize: 25, requestedPort: "56665", resultingPort: "56665"},
+		{name: "bound", searchSize: 25, requestedPort: "56665", boundPort: "56665", resultingPort: "56666"},
+		{name: "default", searchSize: 25, requestedPort: "default to be used", resultingPort: "56664"},
+		{name: "exhausted", searchSize: 1, requestedPort: "default to be used", boundPort: "56664", wantErr: true},
 	}
 
 	for _, tc := range tests {
-		portSearchLoopsBeforePanic = tc.loopLimit
-		ln, lnErr := net.Listen("tcp", ":"+tc.boundPort)
+		t.Run(tc.name, func(t *testing.T) {
+			portSearchSize = tc.searchSize
+			if tc.boundPort != "" {
+				defer bind(t, AllInterfaces, tc.boundPort).Close()
+			}
 
-		if tc.resultingPort != "panic" {
-			resultingPort := FreeUpFrom(tc.requestedPort)
-			if resultingPort != tc.resultingPort {
-				t.Errorf("Expected %s, got %s", tc.resultingPort, resultingPort)
+			resultingPort, err := FreeUpFrom(tc.requestedPort)
+			if tc.wantErr && err == nil {
+				t.Errorf("Expected an error, got %s", resultingPort)
 			}
-		} else {
-			assert.Panics(t, func() { FreeUpFrom(tc.requestedPort) })
-		}
+			if !tc.wantErr && resultingPort != tc.resultingPort {
+				t.Errorf("Expected %s, got %s (%v)", tc.resultingPort, resultingPort, err)
+			}
+		})
+	}
+	portSearchSize = 25
+}
 
-		if lnErr != nil {
-			ln.Close()
-		}
+func Test_FreeSet(t *testing.T) {
+	defer bind(t, AllInterfaces, "56671").Close()
+
+	free, err := FreeSet(AllInterfaces, []Range{{From: 56670, To: 56680}, {From: 56670, To: 56680}, {From: 56670, To: 56680}}, []string{"56672"})
+	if err != nil {
+		t.Fatalf("FreeSet() error = %v", err)
 	}
+	assert.Equal(t, []string{"56670", "56673", "56674"}, free)
 
+	_, err = FreeSet(AllInterfaces, []Range{{From: 56671, To: 56672}}, []string{"56672"})
+	assert.Error(t, err, "no port in the range is free")
+	_, err = FreeSet(AllInterfaces, []Range{{From: 0, To: 70000}}, []string{})
+	assert.Error(t, err, "invalid range")
+}
+
+func Test_IsValidAndFreeOn(t *testing.T) {
+	defer bind(t, Localhost, "56690").Close()
+
+	if err := IsValidAndFreeOn(Localhost, "56690"); err == nil {
+		t.Errorf("Expected error for bound port on %s", Localhost)
+	}
+	if err := IsValidAndFreeOn(Localhost, "56691"); err != nil {
+		t.Errorf("Unexpected error for free port: %v", err)
+	}
+
+	// IPv6 might not be available where the tests run
+	if ln, err := net.Listen("tcp", "[::1]:0"); err == nil {
+		port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+		if err := IsValidAndFreeOn("::1", port); err == nil {
+			t.Errorf("Expected error for bound port on ::1")
+		}
+		ln.Close()
+	}
 }
 
 func TestValidity_isValid_IsValidAndFree(t *testing.T) {
@@ -60,10 +106,14 @@ func TestValidity_isValid_IsValidAndFree(t *testing.T) {
 		{valid: false, port: "foo"},
 		{valid: false, port: "99999999"},
 		{valid: false, port: "-1"},
+		{valid: false, port: "80.5"},
 	}
 
 	for _, tc := range tests {
 		errorOrNil := isValid(tc.port)
+		if tc.valid && errorOrNil != nil {
+			t.Errorf("Unexpected error for port %s", tc.port)
+		}
 		if !tc.valid && errorOrNil == nil {
 			t.Errorf("Expected error for port %s", tc.port)
 		}
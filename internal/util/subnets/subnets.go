@@ -0,0 +1,136 @@
+/*Package subnets in internal utils is functionality for choosing and validating IPv4 subnets
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package subnets
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+var privateRanges = []string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+}
+
+/*Parse parses an IPv4 subnet in CIDR notation, such as 10.0.0.0/24*/
+func Parse(subnet string) (*net.IPNet, error) {
+	ip, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, errors.New("invalid subnet, expected CIDR notation such as 10.0.0.0/24")
+	}
+	if ip.To4() == nil {
+		return nil, errors.New("subnet must be IPv4")
+	}
+	if !ip.Equal(ipNet.IP) {
+		return nil, errors.New("subnet must start at its network address, such as " + ipNet.String())
+	}
+	ones, _ := ipNet.Mask.Size()
+	if ones > 29 {
+		return nil, errors.New("subnet is too small, use /29 or larger")
+	}
+	return ipNet, nil
+}
+
+/*IsPrivate is the whole subnet within one of the RFC 1918 private ranges*/
+func IsPrivate(subnet *net.IPNet) bool {
+	ones, _ := subnet.Mask.Size()
+	for _, privateRange := range privateRanges {
+		_, private, _ := net.ParseCIDR(privateRange)
+		privateOnes, _ := private.Mask.Size()
+		if private.Contains(subnet.IP) && ones >= privateOnes {
+			return true
+		}
+	}
+	return false
+}
+
+/*Overlaps do the two subnets share any addresses*/
+func Overlaps(a *net.IPNet, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+/*Validate checks that the subnet is a usable private IPv4 subnet*/
+func Validate(subnet string) error {
+	ipNet, err := Parse(subnet)
+	if err != nil {
+		return err
+	}
+	if !IsPrivate(ipNet) {
+		return errors.New("subnet must be within a private range (10.0.0.0/8, 172.16.0.0/12 or 192.168.0.0/16)")
+	}
+	return nil
+}
+
+/*HostAt gets the nth address in the subnet, where 0 is the network address*/
+func HostAt(subnet *net.IPNet, n uint32) net.IP {
+	start := binary.BigEndian.Uint32(subnet.IP.To4())
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, start+n)
+	return ip
+}
+
+/*ValidateHostIP checks that the ip can be given to a container in the subnet*/
+func ValidateHostIP(subnet string, ip string) error {
+	ipNet, err := Parse(subnet)
+	if err != nil {
+		return err
+	}
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil || parsedIP.To4() == nil {
+		return errors.New("invalid IPv4 address")
+	}
+	if !ipNet.Contains(parsedIP) {
+		return errors.New(ip + " is not within " + subnet)
+	}
+	ones, bits := ipNet.Mask.Size()
+	broadcast := HostAt(ipNet, uint32(1)<<uint(bits-ones)-1)
+	if parsedIP.Equal(HostAt(ipNet, 0)) || parsedIP.Equal(HostAt(ipNet, 1)) || parsedIP.Equal(broadcast) {
+		return errors.New(ip + " is reserved as the network, gateway or broadcast address of " + subnet)
+	}
+	return nil
+}
+
+/*FirstFree finds the first /24 within the private ranges that does not overlap any of the used subnets*/
+func FirstFree(used []*net.IPNet) (string, error) {
+	for _, privateRange := range privateRanges {
+		_, private, _ := net.ParseCIDR(privateRange)
+		ones, bits := private.Mask.Size()
+		count := uint32(1) << uint(24-ones)
+		for i := uint32(0); i < count; i++ {
+			candidate := &net.IPNet{
+				IP:   HostAt(private, i<<uint(bits-24)),
+				Mask: net.CIDRMask(24, bits),
+			}
+			if !overlapsAny(candidate, used) {
+				return candidate.String(), nil
+			}
+		}
+	}
+	return "", errors.New("no free private subnet could be found")
+}
+
+func overlapsAny(subnet *net.IPNet, used []*net.IPNet) bool {
+	for _, usedSubnet := range used {
+		if Overlaps(subnet, usedSubnet) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+/*Package subnets in internal utils is functionality for choosing and validating IPv4 subnets
+
+Copyright © 2020 Addshore
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package subnets
+
+import (
+	"net"
+	"testing"
+)
+
+func mustParse(subnets ...string) []*net.IPNet {
+	parsed := []*net.IPNet{}
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			panic(err)
+		}
+		parsed = append(parsed, ipNet)
+	}
+	return parsed
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		subnet string
+		valid  bool
+	}{
+		{subnet: "10.0.0.0/24", valid: true},
+		{subnet: "172.20.0.0/16", valid: true},
+		{subnet: "192.168.5.0/24", valid: true},
+		{subnet: "10.0.0.5/24", valid: false},
+		{subnet: "8.8.8.0/24", valid: false},
+		{subnet: "172.0.0.0/8", valid: false},
+		{subnet: "10.0.0.0/30", valid: false},
+		{subnet: "fd00::/64", valid: false},
+		{subnet: "foo", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subnet, func(t *testing.T) {
+			err := Validate(tt.subnet)
+			if tt.valid && err != nil {
+				t.Errorf("Validate() unexpected error %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Validate() expected an error")
+			}
+		})
+	}
+}
+
+func TestValidateHostIP(t *testing.T) {
+	tests := []struct {
+		subnet string
+		ip     string
+		valid  bool
+	}{
+		{subnet: "10.0.0.0/24", ip: "10.0.0.10", valid: true},
+		{subnet: "10.0.0.0/24", ip: "10.0.0.0", valid: false},
+		{subnet: "10.0.0.0/24", ip: "10.0.0.1", valid: false},
+		{subnet: "10.0.0.0/24", ip: "10.0.0.255", valid: false},
+		{subnet: "10.0.0.0/24", ip: "10.0.1.10", valid: false},
+		{subnet: "10.0.0.0/24", ip: "nope", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subnet+" "+tt.ip, func(t *testing.T) {
+			err := ValidateHostIP(tt.subnet, tt.ip)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateHostIP() unexpected error %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("ValidateHostIP() expected an error")
+			}
+		})
+	}
+}
+
+func TestHostAt(t *testing.T) {
+	subnet := mustParse("10.1.2.0/24")[0]
+	if got := HostAt(subnet, 10).String(); got != "10.1.2.10" {
+		t.Errorf("HostAt() = %v, want 10.1.2.10", got)
+	}
+	if got := HostAt(subnet, 1).String(); got != "10.1.2.1" {
+		t.Errorf("HostAt() = %v, want 10.1.2.1", got)
+	}
+}
+
+func TestFirstFree(t *testing.T) {
+	tests := []struct {
+		name string
+		used []*net.IPNet
+		want string
+	}{
+		{
+			name: "Nothing used",
+			used: mustParse(),
+			want: "10.0.0.0/24",
+		},
+		{
+			name: "First /24 used",
+			used: mustParse("10.0.0.0/24"),
+			want: "10.0.1.0/24",
+		},
+		{
+			name: "Larger overlapping network used",
+			used: mustParse("10.0.0.0/23", "10.0.2.128/25"),
+			want: "10.0.3.0/24",
+		},
+		{
+			name: "All of 10/8 used by a VPN",
+			used: mustParse("10.0.0.0/8", "172.16.0.0/24"),
+			want: "172.16.1.0/24",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FirstFree(tt.used)
+			if err != nil {
+				t.Errorf("FirstFree() unexpected error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FirstFree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
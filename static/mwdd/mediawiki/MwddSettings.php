@@ -13,10 +13,10 @@ if ( !defined( 'MEDIAWIKI' ) ) {
 # MWDD Setup
 ################################
 
-# When used via CLI, use the default DB if no MW_DB is specified
+# When used via CLI, use the MW_DB env var, or the default DB if no MW_DB is specified
 # Maintenance scripts with --wiki passed will set MW_DB
 if ( PHP_SAPI === 'cli' && !defined( 'MW_DB' ) ) {
-    define( 'MW_DB', 'default' );
+    define( 'MW_DB', getenv( 'MW_DB' ) ?: 'default' );
 }
 
 # Detect usage of update.php, so we can turn of replication https://phabricator.wikimedia.org/T283417
@@ -29,7 +29,13 @@ $wgAssumeProxiesUseDefaultProtocolPorts = false;
 # Either use the MW_DB env var, or get the DB from the request
 if ( defined( "MW_DB" ) ) {
     $dockerDb = MW_DB;
-    $wgServer = "//$dockerDb.mediawiki.mwdd.localhost:80";
+    if ( getenv( 'MWDD_HTTPS_PORT' ) ) {
+        $wgServer = "https://$dockerDb.mediawiki.mwdd.localhost:" . getenv( 'MWDD_HTTPS_PORT' );
+    } elseif ( getenv( 'MWDD_PORT' ) ) {
+        $wgServer = "http://$dockerDb.mediawiki.mwdd.localhost:" . getenv( 'MWDD_PORT' );
+    } else {
+        $wgServer = "//$dockerDb.mediawiki.mwdd.localhost:80";
+    }
 } elseif( array_key_exists( 'SERVER_NAME', $_SERVER ) ) {
     $dockerHostParts = explode( '.', $_SERVER['SERVER_NAME'] );
     $dockerDb = $dockerHostParts[0];
@@ -49,10 +55,21 @@ $mwddServices = [
 ################################
 # MWDD Database
 ################################
-// TODO cache the DB existance checks somehow so as not to run on every request...
+// Credentials are set in the .env file of the development environment
+$dockerDbUser = getenv( 'MWDD_DB_USER' ) ?: 'root';
+$dockerDbPassword = getenv( 'MWDD_DB_PASSWORD' ) ?: 'toor';
+
+// Use the record written by the install command, so that we don't need to probe the databases
+$dockerRecordFile = '/mwdd-records/wikis/' . $dockerDb . '.json';
+if( file_exists( $dockerRecordFile ) ) {
+	$dockerRecord = json_decode( file_get_contents( $dockerRecordFile ), true );
+	if( is_array( $dockerRecord ) && in_array( $dockerRecord['type'] ?? null, [ 'sqlite', 'mysql', 'postgres' ] ) ) {
+		$dockerDbType = $dockerRecord['type'];
+	}
+}
 
 // Figure out if we are using sqlite, or if this should be mysql..?
-if( file_exists( $IP . '/data/' . $dockerDb . '.sqlite' ) ) {
+if( !isset( $dockerDbType ) && file_exists( $IP . '/data/' . $dockerDb . '.sqlite' ) ) {
 	$dockerDbType = 'sqlite';
 }
 
@@ -61,8 +78,8 @@ if(!isset($dockerDbType)){
 	try{
 		$mysqlPdo = new PDO(
 			"mysql:host=mysql;dbname=" . $dockerDb,
-			'root',
-			'toor',
+			$dockerDbUser,
+			$dockerDbPassword,
 			[
 				PDO::ATTR_TIMEOUT => 1, // in seconds
 			]
@@ -85,8 +102,8 @@ if(!isset($dockerDbType)){
 if(!isset($dockerDbType)){
 	$pvars = [
 		'dbname' => $dockerDb,
-		'user' => 'root',
-		'password' => 'toor',
+		'user' => $dockerDbUser,
+		'password' => $dockerDbPassword,
 		'host' => 'postgres',
 	];
 	$pconnString = '';
@@ -123,8 +140,8 @@ if( $dockerDbType === 'mysql' ) {
 		[
 			'host' => "mysql",
 			'dbname' => $dockerDb,
-			'user' => 'root',
-			'password' => 'toor',
+			'user' => $dockerDbUser,
+			'password' => $dockerDbPassword,
 			'type' => $dockerDbType,
 			'flags' => DBO_DEFAULT,
 			'load' => $mwddServices['mysql-replica'] ? 0 : 1,
@@ -134,8 +151,8 @@ if( $dockerDbType === 'mysql' ) {
 		$wgDBservers[] = [
 			'host' => "mysql-replica",
 			'dbname' => $dockerDb,
-			'user' => 'root',
-			'password' => 'toor',
+			'user' => $dockerDbUser,
+			'password' => $dockerDbPassword,
 			'type' => $dockerDbType,
 			'flags' => DBO_DEFAULT,
 			# Avoid switching to readonly too early (for example during update.php)
@@ -154,8 +171,8 @@ if( $dockerDbType === 'postgres' ) {
 		[
 			'host' => "postgres",
 			'dbname' => $dockerDb,
-			'user' => 'root',
-			'password' => 'toor',
+			'user' => $dockerDbUser,
+			'password' => $dockerDbPassword,
 			'type' => $dockerDbType,
 			'flags' => DBO_DEFAULT,
 			'load' => 1,
@@ -249,7 +266,15 @@ $wgSecretKey = "j8093j903j902jfr9j109j109jf1093jf09j190jfj09fj1jfknnccnmxnmx";
 $wgPhpCli = '/usr/local/bin/php';
 
 ################################
-# MWDD END
+# MWDD settings.d
 ################################
+// Files from all/ are loaded for every wiki, then files from <dbname>/ for this wiki only
+foreach ( [ 'all', $dockerDb ] as $dockerSettingsDir ) {
+	foreach ( glob( "/mwdd-settings.d/$dockerSettingsDir/*.php" ) ?: [] as $dockerSettingsFile ) {
+		require_once $dockerSettingsFile;
+	}
+}
 
-// TODO add auto loading of other LocalSetting.php files from a directory based on dbname -> file name...
\ No newline at end of file
+################################
+# MWDD END
+################################
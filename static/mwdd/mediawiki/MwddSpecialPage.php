@@ -1,7 +1,12 @@
 <?php
 
+use MediaWiki\MediaWikiServices;
+
 class MwddSpecial extends SpecialPage {
 
+	/** Written by the mwcli after each command, see the records mount in mediawiki.yml */
+	private const STATUS_FILE = '/mwdd-records/status.json';
+
 	public function __construct() {
 		parent::__construct( 'Mwdd' );
 	}
@@ -12,22 +17,145 @@ class MwddSpecial extends SpecialPage {
 	 * @param string|null $subPage
 	 */
 	public function execute( $subPage ) {
+		$status = $this->getStatus();
+
+		// Special:Mwdd/json is queried by `mw docker status --wiki`
+		if ( $subPage === 'json' ) {
+			$this->getOutput()->disable();
+			header( 'Content-Type: application/json' );
+			echo json_encode( $status, JSON_PRETTY_PRINT );
+			return;
+		}
+
 		parent::execute( $subPage );
-		global $mwddServices, $dockerDbType;
+		$out = $this->getOutput();
+		$out->addHTML( $this->section( 'Services', $this->servicesTable( $status['services'], $status['cli']['links'] ?? [] ) ) );
+		$out->addHTML( $this->section( 'Database', $this->databaseTable( $status['db'] ) ) );
+		$out->addHTML( $this->section( 'Caches', $this->keyValueTable( $status['caches'] ) ) );
+		$out->addHTML( $this->section( 'Versions', $this->keyValueTable( $status['versions'] ) ) );
+		$out->addHTML( $this->section( 'Extensions and skins', $this->keyValueTable( $status['extensions'] ) ) );
+		if ( $status['cli'] === null ) {
+			$out->addHTML( Html::element( 'p', [], 'No status from the mwcli was found, run any `mw docker` command to write it.' ) );
+		} else {
+			$out->addHTML( Html::element( 'p', [], 'Status from the mwcli generated at ' . $status['cli']['generated'] ) );
+		}
+	}
+
+	/**
+	 * Everything known about the environment, from this wiki and the mwcli status file
+	 */
+	private function getStatus(): array {
+		global $mwddServices, $dockerDbType, $dockerDb, $wgDBservers;
+		global $wgMainCacheType, $wgParserCacheType, $wgSessionCacheType, $wgMessageCacheType;
+
+		$cli = null;
+		if ( file_exists( self::STATUS_FILE ) ) {
+			$cli = json_decode( file_get_contents( self::STATUS_FILE ), true );
+		}
+
+		$lb = MediaWikiServices::getInstance()->getDBLoadBalancer();
+		$lagTimes = $lb->getLagTimes();
+		$servers = [];
+		foreach ( $wgDBservers as $i => $server ) {
+			$servers[] = [
+				'host' => $server['host'] ?? $server['dbDirectory'] ?? '',
+				'load' => $server['load'],
+				'lag' => $lagTimes[$i] ?? null,
+			];
+		}
+
+		// Services that this wiki uses, plus everything that the mwcli saw running
+		$services = $mwddServices;
+		foreach ( $cli['services'] ?? [] as $service ) {
+			$services[$service] = true;
+		}
+		ksort( $services );
 
-		// TODO output db type, dbname & other db settings here
-		$this->getOutput()->addHTML( "Which services are running?" );
-		$this->getOutput()->addHTML( "</br>" );
-		$this->getOutput()->addHTML( json_encode( $mwddServices ) );
-		$this->getOutput()->addHTML( "</br>" );
-		$this->getOutput()->addHTML( "Which DB is being used" );
-		$this->getOutput()->addHTML( "</br>" );
-		$this->getOutput()->addHTML( $dockerDbType );
-		$this->getOutput()->addHTML( "</br>" );
-		$this->getOutput()->addHTML( "How does DB lag look?" );
-		$this->getOutput()->addHTML( "</br>" );
-		$this->getOutput()->addHTML( json_encode( \MediaWiki\MediaWikiServices::getInstance()->getDBLoadBalancer()->getMaxLag()[1] ) );
+		$extensions = [];
+		foreach ( ExtensionRegistry::getInstance()->getAllThings() as $name => $info ) {
+			$extensions[$name] = $info['version'] ?? '';
+		}
+		ksort( $extensions );
+
+		return [
+			'wiki' => $dockerDb,
+			'services' => $services,
+			'db' => [
+				'type' => $dockerDbType,
+				'name' => $dockerDb,
+				'servers' => $servers,
+			],
+			'caches' => [
+				'main' => $this->cacheName( $wgMainCacheType ),
+				'parser' => $this->cacheName( $wgParserCacheType ),
+				'session' => $this->cacheName( $wgSessionCacheType ),
+				'message' => $this->cacheName( $wgMessageCacheType ),
+			],
+			'versions' => [
+				'MediaWiki' => defined( 'MW_VERSION' ) ? MW_VERSION : $GLOBALS['wgVersion'],
+				'PHP' => PHP_VERSION,
+				'Xdebug' => phpversion( 'xdebug' ) ?: 'not loaded',
+				'Xdebug mode' => ini_get( 'xdebug.mode' ) ?: '',
+				'mwcli' => $cli['cliVersion'] ?? 'unknown',
+			],
+			'extensions' => $extensions,
+			'cli' => $cli,
+		];
+	}
+
+	private function cacheName( $type ): string {
+		$names = [
+			CACHE_NONE => 'none',
+			CACHE_ANYTHING => 'anything',
+			CACHE_DB => 'db',
+			CACHE_ACCEL => 'accel',
+		];
+		return $names[$type] ?? (string)$type;
+	}
+
+	private function section( string $title, string $html ): string {
+		return Html::element( 'h2', [], $title ) . $html;
+	}
+
+	private function servicesTable( array $services, array $links ): string {
+		$rows = '';
+		foreach ( $services as $service => $running ) {
+			$link = isset( $links[$service] ) ? Html::element( 'a', [ 'href' => $links[$service] ], $links[$service] ) : '';
+			$rows .= Html::rawElement( 'tr', [],
+				Html::element( 'td', [], $service ) .
+				Html::element( 'td', [], $running ? 'running' : 'not running' ) .
+				Html::rawElement( 'td', [], $link )
+			);
+		}
+		return Html::rawElement( 'table', [ 'class' => 'wikitable' ], $rows );
+	}
+
+	private function databaseTable( array $db ): string {
+		$html = $this->keyValueTable( [ 'Type' => $db['type'], 'Name' => $db['name'] ] );
+		$rows = Html::rawElement( 'tr', [],
+			Html::element( 'th', [], 'Server' ) .
+			Html::element( 'th', [], 'Load' ) .
+			Html::element( 'th', [], 'Replication lag (seconds)' )
+		);
+		foreach ( $db['servers'] as $server ) {
+			$rows .= Html::rawElement( 'tr', [],
+				Html::element( 'td', [], $server['host'] ) .
+				Html::element( 'td', [], (string)$server['load'] ) .
+				Html::element( 'td', [], is_numeric( $server['lag'] ) ? (string)$server['lag'] : 'unknown' )
+			);
+		}
+		return $html . Html::rawElement( 'table', [ 'class' => 'wikitable' ], $rows );
+	}
 
+	private function keyValueTable( array $values ): string {
+		$rows = '';
+		foreach ( $values as $key => $value ) {
+			$rows .= Html::rawElement( 'tr', [],
+				Html::element( 'th', [], (string)$key ) .
+				Html::element( 'td', [], (string)$value )
+			);
+		}
+		return Html::rawElement( 'table', [ 'class' => 'wikitable' ], $rows );
 	}
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,72 @@
+<?php
+
+use MediaWiki\MediaWikiServices;
+
+require_once getenv( 'MW_INSTALL_PATH' ) . '/maintenance/Maintenance.php';
+
+/**
+ * Lists users, and changes the groups of a user, for `mw docker mediawiki user`
+ */
+class MwddUsers extends Maintenance {
+
+	public function __construct() {
+		parent::__construct();
+		$this->addDescription( 'Lists users, or adds and removes a user from groups' );
+		$this->addOption( 'group', 'Only list users in this group', false, true );
+		$this->addOption( 'add', 'Comma separated groups to add the user to', false, true );
+		$this->addOption( 'remove', 'Comma separated groups to remove the user from', false, true );
+		$this->addArg( 'user', 'User to change the groups of, all users are listed without it', false );
+	}
+
+	public function execute() {
+		$groupManager = MediaWikiServices::getInstance()->getUserGroupManager();
+
+		if ( !$this->hasArg( 0 ) ) {
+			foreach ( $this->userIds() as $id ) {
+				$this->outputUser( User::newFromId( $id ) );
+			}
+			return;
+		}
+
+		$user = User::newFromName( $this->getArg( 0 ) );
+		if ( !$user || !$user->isRegistered() ) {
+			$this->fatalError( 'No such user: ' . $this->getArg( 0 ) );
+		}
+		foreach ( $this->groupsOption( 'add' ) as $group ) {
+			$groupManager->addUserToGroup( $user, $group );
+		}
+		foreach ( $this->groupsOption( 'remove' ) as $group ) {
+			$groupManager->removeUserFromGroup( $user, $group );
+		}
+		$this->outputUser( $user );
+	}
+
+	private function groupsOption( string $name ): array {
+		return array_filter( array_map( 'trim', explode( ',', $this->getOption( $name, '' ) ) ) );
+	}
+
+	private function userIds(): array {
+		$dbr = $this->getDB( DB_REPLICA );
+		$tables = [ 'user' ];
+		$conds = [];
+		$joins = [];
+		if ( $this->hasOption( 'group' ) ) {
+			$tables[] = 'user_groups';
+			$conds['ug_group'] = $this->getOption( 'group' );
+			$joins['user_groups'] = [ 'JOIN', 'ug_user = user_id' ];
+		}
+		return $dbr->selectFieldValues( $tables, 'user_id', $conds, __METHOD__, [ 'ORDER BY' => 'user_name' ], $joins );
+	}
+
+	/**
+	 * Tab separated, for the mwcli to format
+	 */
+	private function outputUser( User $user ) {
+		$groups = MediaWikiServices::getInstance()->getUserGroupManager()->getUserGroups( $user );
+		$this->output( $user->getName() . "\t" . implode( ',', $groups ) . "\n" );
+	}
+
+}
+
+$maintClass = MwddUsers::class;
+require_once RUN_MAINTENANCE_IF_MAIN;
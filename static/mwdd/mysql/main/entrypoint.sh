@@ -6,5 +6,14 @@ rm -rf /etc/mysql/conf.d/*.cnf
 cp /mwdd-custom/custom.cnf /etc/mysql/conf.d/mwdd-custom.cnf
 chmod 0444 /etc/mysql/conf.d/mwdd-custom.cnf
 
+# The regular entrypoint only creates root, so give a differently named root user the same privileges when the data is first created
+if [ -n "$MYSQL_ROOT_USER" ] && [ "$MYSQL_ROOT_USER" != "root" ]; then
+    mkdir -p /docker-entrypoint-initdb.d
+    cat > /docker-entrypoint-initdb.d/mwdd-root-user.sql <<EOF
+CREATE USER IF NOT EXISTS '$MYSQL_ROOT_USER'@'%' IDENTIFIED BY '$MYSQL_ROOT_PASSWORD';
+GRANT ALL PRIVILEGES ON *.* TO '$MYSQL_ROOT_USER'@'%' WITH GRANT OPTION;
+EOF
+fi
+
 # Then execute the regular mysql / mariadb entrypoint
 /usr/local/bin/docker-entrypoint.sh $@
@@ -20,8 +20,8 @@ sleep 1
 /wait-for-it.sh  mysql:3306
 
 echo "* Get the binlog file and position"
-MYSQL01_Position=$(eval "mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -e 'show master status \G' | grep Position | sed -n -e 's/^.*: //p'")
-MYSQL01_File=$(eval "mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -e 'show master status \G'     | grep File     | sed -n -e 's/^.*: //p'")
+MYSQL01_Position=$(eval "mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -e 'show master status \G' | grep Position | sed -n -e 's/^.*: //p'")
+MYSQL01_File=$(eval "mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -e 'show master status \G'     | grep File     | sed -n -e 's/^.*: //p'")
 
 echo "* Saving data to files"
 
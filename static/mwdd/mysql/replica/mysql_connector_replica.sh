@@ -24,12 +24,18 @@ fi
 
 echo "* Create replication user"
 
-mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -AN -e 'STOP SLAVE;';
-mysql --host mysql-replica -uroot -p$MYSQL_MAIN_PASSWORD -AN -e 'RESET SLAVE ALL;';
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -AN -e 'STOP SLAVE;';
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e 'RESET SLAVE ALL;';
 
-mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -AN -e "CREATE USER '$MYSQL_REPLICATION_USER'@'%';"
-mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -AN -e "GRANT REPLICATION SLAVE ON *.* TO '$MYSQL_REPLICATION_USER'@'%' IDENTIFIED BY '$MYSQL_REPLICATION_PASSWORD';"
-mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -AN -e 'flush privileges;'
+# MySQL 8 no longer accepts IDENTIFIED BY in GRANT, and defaults to caching_sha2_password which replicas can only use over SSL
+REPLICATION_AUTH="IDENTIFIED BY '$MYSQL_REPLICATION_PASSWORD'"
+if ! mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e 'SELECT VERSION();' | grep -qi mariadb; then
+    REPLICATION_AUTH="IDENTIFIED WITH mysql_native_password BY '$MYSQL_REPLICATION_PASSWORD'"
+fi
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e "CREATE USER IF NOT EXISTS '$MYSQL_REPLICATION_USER'@'%' $REPLICATION_AUTH;"
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e "ALTER USER '$MYSQL_REPLICATION_USER'@'%' $REPLICATION_AUTH;"
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e "GRANT REPLICATION SLAVE ON *.* TO '$MYSQL_REPLICATION_USER'@'%';"
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e 'flush privileges;'
 
 
 echo "* Set MySQL01 as master on MySQL02"
@@ -40,29 +46,29 @@ MYSQL01_File=$(<$file_file)
 
 MAIN_IP=$(eval "getent hosts mysql|awk '{print \$1}'")
 echo $MAIN_IP
-mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -AN -e "CHANGE MASTER TO master_host='mysql', master_port=3306, \
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -AN -e "CHANGE MASTER TO master_host='mysql', master_port=3306, \
         master_user='$MYSQL_REPLICATION_USER', master_password='$MYSQL_REPLICATION_PASSWORD', master_log_file='$MYSQL01_File', \
         master_log_pos=$MYSQL01_Position;"
 
 echo "* Set MySQL02 as master on MySQL01"
 
-MYSQL02_Position=$(eval "mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -e 'show master status \G' | grep Position | sed -n -e 's/^.*: //p'")
-MYSQL02_File=$(eval "mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -e 'show master status \G'     | grep File     | sed -n -e 's/^.*: //p'")
+MYSQL02_Position=$(eval "mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -e 'show master status \G' | grep Position | sed -n -e 's/^.*: //p'")
+MYSQL02_File=$(eval "mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -e 'show master status \G'     | grep File     | sed -n -e 's/^.*: //p'")
 
 REPLICA_IP=$(eval "getent hosts mysql-replica|awk '{print \$1}'")
 echo $REPLICA_IP
-mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -AN -e "CHANGE MASTER TO master_host='mysql-replica', master_port=3306, \
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e "CHANGE MASTER TO master_host='mysql-replica', master_port=3306, \
         master_user='$MYSQL_REPLICATION_USER', master_password='$MYSQL_REPLICATION_PASSWORD', master_log_file='$MYSQL02_File', \
         master_log_pos=$MYSQL02_Position;"
 
 echo "* Start Replica on both Servers"
-mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -AN -e "start slave;"
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -AN -e "start slave;"
 
 echo "Increase the max_connections to 1000"
-mysql --host mysql -uroot -p$MYSQL_MAIN_PASSWORD -AN -e 'set GLOBAL max_connections=1000';
-mysql --host mysql-replica -uroot -p$MYSQL_REPLICA_PASSWORD -AN -e 'set GLOBAL max_connections=1000';
+mysql --host mysql -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -AN -e 'set GLOBAL max_connections=1000';
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_REPLICA_PASSWORD -AN -e 'set GLOBAL max_connections=1000';
 
-mysql --host mysql-replica -uroot -p$MYSQL_MAIN_PASSWORD -e "show slave status \G"
+mysql --host mysql-replica -u$MYSQL_ROOT_USER -p$MYSQL_MAIN_PASSWORD -e "show slave status \G"
 
 echo "MySQL servers created!"
 echo "--------------------"